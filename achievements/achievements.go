@@ -0,0 +1,188 @@
+package achievements
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/records"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Metrics an achievement can be unlocked on
+const (
+	MetricRaces       = "races"
+	MetricWins        = "wins"
+	MetricWPM         = "wpm"
+	MetricAccuracy    = "accuracy"
+	MetricWinStreak   = "win_streak"
+	MetricDailyStreak = "daily_streak"
+)
+
+// Achievement unlocks once Metric reaches Threshold in a race of at least MinLength typed characters
+type Achievement struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Metric      string  `json:"metric"`
+	Threshold   float64 `json:"threshold"`
+	MinLength   int     `json:"min_length,omitempty"`
+}
+
+// Catalog lists every achievement, keys are stored per user so they must never change
+var Catalog = []Achievement{
+	{Key: "first_win", Name: "First Blood", Description: "Win your first race", Metric: MetricWins, Threshold: 1},
+	{Key: "wins_50", Name: "Contender", Description: "Win 50 races", Metric: MetricWins, Threshold: 50},
+	{Key: "races_10", Name: "Warming Up", Description: "Finish 10 races", Metric: MetricRaces, Threshold: 10},
+	{Key: "races_100", Name: "Centurion", Description: "Finish 100 races", Metric: MetricRaces, Threshold: 100},
+	{Key: "wpm_60", Name: "Quick Fingers", Description: "Reach 60 WPM in a race", Metric: MetricWPM, Threshold: 60},
+	{Key: "wpm_100", Name: "Triple Digits", Description: "Reach 100 WPM in a race", Metric: MetricWPM, Threshold: 100},
+	{Key: "accuracy_99_long", Name: "Sharpshooter", Description: "Get 99% accuracy over 300 or more typed characters", Metric: MetricAccuracy, Threshold: 99, MinLength: 300},
+	{Key: "win_streak_10", Name: "Unstoppable", Description: "Win 10 races in a row", Metric: MetricWinStreak, Threshold: 10},
+	{Key: "daily_streak_7", Name: "Regular", Description: "Race on 7 days in a row", Metric: MetricDailyStreak, Threshold: 7},
+}
+
+var byKey = func() map[string]Achievement {
+	m := make(map[string]Achievement, len(Catalog))
+	for _, a := range Catalog {
+		m[a.Key] = a
+	}
+	return m
+}()
+
+// Stats is what a race leaves the player with, the input to every rule
+type Stats struct {
+	Races       int
+	Wins        int
+	WPM         int
+	Accuracy    float64
+	Typed       int
+	WinStreak   int
+	DailyStreak int
+}
+
+func (s Stats) value(metric string) float64 {
+	switch metric {
+	case MetricRaces:
+		return float64(s.Races)
+	case MetricWins:
+		return float64(s.Wins)
+	case MetricWPM:
+		return float64(s.WPM)
+	case MetricAccuracy:
+		return s.Accuracy
+	case MetricWinStreak:
+		return float64(s.WinStreak)
+	case MetricDailyStreak:
+		return float64(s.DailyStreak)
+	}
+	return 0
+}
+
+// Met reports whether the stats satisfy the achievement
+func (a Achievement) Met(s Stats) bool {
+	if a.MinLength > 0 && s.Typed < a.MinLength {
+		return false
+	}
+	return s.value(a.Metric) >= a.Threshold
+}
+
+// Unlock is an achievement a user holds
+type Unlock struct {
+	Achievement
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// statsFor gathers the player's totals, streaks must already be updated in tx
+func statsFor(tx *gorm.DB, result models.Results) (Stats, error) {
+	stats := Stats{
+		WPM:      result.WPM,
+		Accuracy: result.Accuracy,
+		Typed:    result.Typed,
+	}
+
+	var total struct {
+		Races int
+		Wins  int
+	}
+	err := tx.Model(&models.Results{}).
+		Select("COUNT(*) AS races, COUNT(*) FILTER (WHERE won) AS wins").
+		Where("user_id = ?", result.UserID).
+		Scan(&total).Error
+	if err != nil {
+		return stats, err
+	}
+	stats.Races = total.Races
+	stats.Wins = total.Wins
+
+	streak, err := records.Streak(tx, result.UserID, result.CreatedAt)
+	if err != nil {
+		return stats, err
+	}
+	stats.WinStreak = streak.WinStreak
+	stats.DailyStreak = streak.DailyStreak
+
+	return stats, nil
+}
+
+// Evaluate stores and returns the achievements a freshly recorded result unlocked
+func Evaluate(tx *gorm.DB, result models.Results) ([]Unlock, error) {
+	var held []string
+	if err := tx.Model(&models.UserAchievement{}).
+		Where("user_id = ?", result.UserID).
+		Pluck("key", &held).Error; err != nil {
+		return nil, err
+	}
+	owned := make(map[string]bool, len(held))
+	for _, key := range held {
+		owned[key] = true
+	}
+
+	stats, err := statsFor(tx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	unlockedAt := result.CreatedAt
+	if unlockedAt.IsZero() {
+		unlockedAt = time.Now()
+	}
+
+	var unlocked []Unlock
+	for _, a := range Catalog {
+		if owned[a.Key] || !a.Met(stats) {
+			continue
+		}
+		row := models.UserAchievement{
+			UserID:     result.UserID,
+			Key:        a.Key,
+			ResultID:   &result.ID,
+			UnlockedAt: unlockedAt,
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected > 0 {
+			unlocked = append(unlocked, Unlock{Achievement: a, UnlockedAt: unlockedAt})
+		}
+	}
+	return unlocked, nil
+}
+
+// ForUser lists the user's unlocked achievements still in the catalog, most recent first
+func ForUser(db *gorm.DB, userID uuid.UUID) ([]Unlock, error) {
+	var rows []models.UserAchievement
+	if err := db.Where("user_id = ?", userID).Order("unlocked_at DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	unlocks := make([]Unlock, 0, len(rows))
+	for _, row := range rows {
+		if a, ok := byKey[row.Key]; ok {
+			unlocks = append(unlocks, Unlock{Achievement: a, UnlockedAt: row.UnlockedAt})
+		}
+	}
+	return unlocks, nil
+}
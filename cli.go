@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+)
+
+// runPromptsCommand handles `go run . prompts import|export [-format] [-file]`
+func runPromptsCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: prompts import|export [-format json|csv|text] [-file path]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("prompts "+args[0], flag.ContinueOnError)
+	format := fs.String("format", "", "json, csv or text (defaults to the file extension)")
+	file := fs.String("file", "", "file to read or write (defaults to stdin/stdout)")
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if *format == "" {
+		*format = prompts.FormatFromName(*file)
+	}
+
+	switch args[0] {
+	case "import":
+		var in io.Reader = os.Stdin
+		if *file != "" {
+			f, err := os.Open(*file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to open %s: %v\n", *file, err)
+				return 1
+			}
+			defer f.Close()
+			in = f
+		}
+
+		records, rowErrors, err := prompts.Parse(in, *format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse prompts: %v\n", err)
+			return 1
+		}
+
+		report, err := prompts.Import(config.DB, records, rowErrors)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to import prompts: %v\n", err)
+			return 1
+		}
+
+		fmt.Printf("%d rows: %d created, %d duplicates, %d errors\n",
+			report.Total, report.Created, report.Duplicates, len(report.Errors))
+		for _, e := range report.Errors {
+			fmt.Printf("  row %d: %s\n", e.Row, e.Error)
+		}
+		if len(report.Errors) > 0 {
+			return 1
+		}
+		return 0
+
+	case "export":
+		var out io.Writer = os.Stdout
+		if *file != "" {
+			f, err := os.Create(*file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", *file, err)
+				return 1
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err := prompts.Export(config.DB, out, *format); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to export prompts: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown prompts command %q\n", args[0])
+	return 2
+}
@@ -31,7 +31,24 @@ func ConnectDB() {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Room{}, &models.Results{})
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Room{},
+		&models.Results{},
+		&models.UserRating{},
+		&models.RatingHistory{},
+		&models.LeaderboardStat{},
+		&models.Season{},
+		&models.SeasonStanding{},
+		&models.Prompt{},
+		&models.PromptStat{},
+		&models.DailyChallenge{},
+		&models.DailyAttempt{},
+		&models.KeyStat{},
+		&models.PersonalBest{},
+		&models.UserStreak{},
+		&models.UserAchievement{},
+	)
 
 	if err != nil {
 		log.Fatalf("Error migrating database: %v", err)
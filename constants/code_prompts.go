@@ -0,0 +1,129 @@
+package constants
+
+type CodePrompt struct {
+	ID       int    `json:"id"`
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// CodePrompts are the built-in snippets for code races, indented with tabs
+var CodePrompts = []CodePrompt{
+	{
+		ID:       201,
+		Language: "go",
+		Code: `func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}`,
+	},
+	{
+		ID:       202,
+		Language: "go",
+		Code: `func (s *Stack) Pop() (int, error) {
+	if len(s.items) == 0 {
+		return 0, errors.New("stack is empty")
+	}
+	top := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return top, nil
+}`,
+	},
+	{
+		ID:       203,
+		Language: "python",
+		Code: `def fibonacci(n):
+	a, b = 0, 1
+	for _ in range(n):
+		yield a
+		a, b = b, a + b
+
+print(list(fibonacci(10)))`,
+	},
+	{
+		ID:       204,
+		Language: "python",
+		Code: `def word_counts(path):
+	counts = {}
+	with open(path) as f:
+		for line in f:
+			for word in line.split():
+				counts[word] = counts.get(word, 0) + 1
+	return sorted(counts.items(), key=lambda kv: -kv[1])`,
+	},
+	{
+		ID:       205,
+		Language: "javascript",
+		Code: `function debounce(fn, wait) {
+	let timer = null;
+	return (...args) => {
+		clearTimeout(timer);
+		timer = setTimeout(() => fn(...args), wait);
+	};
+}`,
+	},
+	{
+		ID:       206,
+		Language: "javascript",
+		Code: `const groupBy = (items, key) =>
+	items.reduce((groups, item) => {
+		const value = item[key];
+		(groups[value] ||= []).push(item);
+		return groups;
+	}, {});`,
+	},
+	{
+		ID:       207,
+		Language: "rust",
+		Code: `fn gcd(mut a: u64, mut b: u64) -> u64 {
+	while b != 0 {
+		let t = b;
+		b = a % b;
+		a = t;
+	}
+	a
+}`,
+	},
+	{
+		ID:       208,
+		Language: "sql",
+		Code: `SELECT u.username, COUNT(r.id) AS races, AVG(r.wpm) AS avg_wpm
+FROM users u
+JOIN results r ON r.user_id = u.id
+WHERE r.created_at > NOW() - INTERVAL '7 days'
+GROUP BY u.username
+ORDER BY avg_wpm DESC
+LIMIT 10;`,
+	},
+	{
+		ID:       209,
+		Language: "c",
+		Code: `int binary_search(const int *arr, int n, int target) {
+	int lo = 0, hi = n - 1;
+	while (lo <= hi) {
+		int mid = lo + (hi - lo) / 2;
+		if (arr[mid] == target) return mid;
+		if (arr[mid] < target) lo = mid + 1;
+		else hi = mid - 1;
+	}
+	return -1;
+}`,
+	},
+	{
+		ID:       210,
+		Language: "typescript",
+		Code: `interface Player {
+	username: string;
+	wpm: number;
+}
+
+export function fastest(players: Player[]): Player | undefined {
+	return players.reduce<Player | undefined>(
+		(best, p) => (!best || p.wpm > best.wpm ? p : best),
+		undefined,
+	);
+}`,
+	},
+}
@@ -0,0 +1,56 @@
+package constants
+
+type LocalizedPrompt struct {
+	ID       int    `json:"id"`
+	Language string `json:"language"`
+	Prompt   string `json:"prompt"`
+}
+
+// LocalizedPrompts are built-in passages in languages other than English
+var LocalizedPrompts = []LocalizedPrompt{
+	{
+		ID:       301,
+		Language: "fr",
+		Prompt:   "Le vieux phare se dressait au bord de la falaise. Chaque nuit, sa lumière balayait la mer agitée et guidait les pêcheurs jusqu'au port, même quand la tempête faisait rage.",
+	},
+	{
+		ID:       302,
+		Language: "fr",
+		Prompt:   "Élise ouvrit la fenêtre du café et respira l'odeur du pain chaud. Dans la rue, les enfants couraient déjà vers l'école en riant, leurs cartables rebondissant sur leur dos.",
+	},
+	{
+		ID:       303,
+		Language: "de",
+		Prompt:   "Über den Dächern der Stadt zogen dunkle Wolken auf. Die Straßenbahn fuhr langsam durch die engen Gassen, während die Menschen ihre Schirme öffneten und schneller gingen.",
+	},
+	{
+		ID:       304,
+		Language: "es",
+		Prompt:   "El tren salió de la estación al amanecer. Por la ventana, María vio los campos dorados y las montañas lejanas, y pensó que por fin empezaba el viaje que había soñado durante años.",
+	},
+	{
+		ID:       305,
+		Language: "pt",
+		Prompt:   "A praia estava quase vazia naquela manhã de inverno. Só um pescador solitário caminhava junto ao mar, com a rede às costas e o olhar perdido no horizonte.",
+	},
+	{
+		ID:       306,
+		Language: "vi",
+		Prompt:   "Buổi sáng ở Hà Nội bắt đầu bằng tiếng xe máy và mùi phở thơm lừng. Người dân ngồi bên vỉa hè, uống cà phê và trò chuyện về một ngày mới.",
+	},
+	{
+		ID:       307,
+		Language: "ja",
+		Prompt:   "静かな夜、月の光が庭を照らしていた。古い木の下で猫が丸くなって眠り、遠くから電車の音がかすかに聞こえてきた。",
+	},
+	{
+		ID:       308,
+		Language: "zh",
+		Prompt:   "清晨的公园里，老人们在打太极拳，孩子们在草地上奔跑。湖面上飘着薄薄的雾，一只白鹭慢慢地飞过水面。",
+	},
+	{
+		ID:       309,
+		Language: "ko",
+		Prompt:   "비가 그친 오후, 골목길에는 따뜻한 햇살이 비쳤다. 작은 빵집 앞에는 갓 구운 빵 냄새를 맡은 사람들이 줄을 서 있었다.",
+	},
+}
@@ -1,14 +1,12 @@
 package constants
 
-import (
-	"math/rand"
-)
-
 type Prompt struct {
 	ID     int    `json:"id"`
 	Prompt string `json:"prompt"`
 }
 
+// Prompts is the built-in set the prompt library is seeded with
+
 var Prompts = []Prompt{
 	{
 		ID: 1,
@@ -92,9 +90,87 @@ var Prompts = []Prompt{
 	},
 }
 
-// returns a random prompt from the list of prompts
 
-func GetRandomPrompt() string {
-	randomIndex := rand.Intn(len(Prompts))
-	return Prompts[randomIndex].Prompt
-}
\ No newline at end of file
+// PunctuatedPrompts are the same stories with casing, punctuation and numbers
+var PunctuatedPrompts = []Prompt{
+	{
+		ID: 101,
+		Prompt: "The ancient stone tablet was covered in a language no one could decipher. Intricate carvings depicted 12 constellations and mythical beasts, hinting at a lost civilization's knowledge of the cosmos. An archeologist carefully dusted the surface, revealing a single glowing symbol that pulsed with a faint, ethereal light.",
+	},
+	{
+		ID: 102,
+		Prompt: "A lone spaceship drifted through the silent, star-filled void. Its engine, a marvel of intergalactic engineering, had failed. The pilot, a grizzled veteran of 37 deep-space missions, initiated a desperate reboot sequence, hoping to restore power before the ship's life support systems gave out completely.",
+	},
+	{
+		ID: 103,
+		Prompt: "She carefully mixed the potions in her cauldron. Each ingredient was added with precision: a pinch of moonflower, 3 drops of captured starlight, and a whispered incantation. The liquid inside shimmered with an otherworldly glow, promising to grant her the power to see into the future... but at a terrible price.",
+	},
+	{
+		ID: 104,
+		Prompt: "The detective stared at the cryptic note left at the scene of the crime. The handwriting was elegant, almost artistic, but the message itself was a puzzle of riddles and metaphors. He knew the solution lay hidden within the poetry: a secret message revealing the next move of a brilliant criminal mastermind.",
+	},
+	{
+		ID: 105,
+		Prompt: "A small robotic companion scurried through the overgrown ruins of a forgotten city. Its primary directive was to find and retrieve a lost data chip, but its sensors detected something far more significant. A faint signal emanated from deep within the rubble: a message from an ancient, long-vanished artificial intelligence.",
+	},
+	{
+		ID: 106,
+		Prompt: "The old lighthouse keeper polished the lens, a ritual he had performed for 40 years. A storm was brewing on the horizon, and the light's beam was the only beacon of hope for ships caught in the tumultuous waves. He watched the churning sea, a testament to nature's untamable power.",
+	},
+	{
+		ID: 107,
+		Prompt: "She opened the antique music box, and a delicate, haunting melody filled the room. The tiny ballerina inside pirouetted gracefully, a silent dance that evoked memories of a childhood long past. The music box, a gift from her grandmother in 1952, held a secret compartment she had never discovered before.",
+	},
+	{
+		ID: 108,
+		Prompt: "The master thief crept through the museum's security laser grid. His movements were fluid and silent; he was a ghost in the night. His target was a priceless, 45-carat diamond, a relic of a forgotten kingdom. Every step was a calculated risk. One wrong move, and the silent alarm would trigger.",
+	},
+	{
+		ID: 109,
+		Prompt: "The wizard's apprentice practiced his first spell, a simple levitation charm. With a flick of his wrist and a whispered word of power, a small feather rose from the table, hovering precariously in the air. It was a clumsy attempt, but a testament to his burgeoning magical abilities.",
+	},
+	{
+		ID: 110,
+		Prompt: "A group of 6 explorers trekked through the dense, uncharted jungle. A map drawn on brittle parchment guided their way to a legendary lost temple. The air was thick with the sounds of exotic wildlife, and every rustle of leaves could signal a hidden danger. They pushed forward, driven by curiosity and the promise of discovery.",
+	},
+	{
+		ID: 111,
+		Prompt: "The sentient robot painter carefully selected its colors. A canvas awaited, blank and full of possibility. The artist program, a complex algorithm of aesthetics and emotion, guided its metallic hand. It began to paint, creating a masterpiece that expressed a machine's unique interpretation of human feelings and the natural world.",
+	},
+	{
+		ID: 112,
+		Prompt: "He found the forgotten diary in the attic, its pages yellowed and brittle with age. The ink was faded, but the words told a gripping tale of adventure, betrayal, and a hidden treasure. He realized the diary's author, born in 1887, was his great-grandfather, and the treasure was still waiting to be found.",
+	},
+	{
+		ID: 113,
+		Prompt: "The captain of the airship surveyed the clouds below. They formed a sea of white, a vast, endless landscape in the sky. His crew of 14 were busy with their duties, preparing for a long journey to a floating city. The airship's steam engines hummed with a powerful, rhythmic beat: a constant reminder of their upward momentum.",
+	},
+	{
+		ID: 114,
+		Prompt: "A mysterious portal shimmered in the center of the forest. It pulsed with a soft, inviting light, hinting at another world beyond its swirling surface. A young adventurer, driven by a thirst for the unknown, took a deep breath and stepped through, ready to face whatever lay on the other side.",
+	},
+	{
+		ID: 115,
+		Prompt: "The dragon, a magnificent beast of scales and fire, rested atop a mountain peak. Its hoard of gold and jewels glittered in the afternoon sun: a king's ransom. But the dragon was not a creature of greed. It was the sworn protector of the mountain, a silent guardian of an ancient prophecy.",
+	},
+	{
+		ID: 116,
+		Prompt: "The young alchemist finally created the Philosopher's Stone. It wasn't a glittering gem, but a simple polished stone that radiated a quiet warmth. He held it in his hand, a tangible symbol of his 10 years of tireless study and experimentation. The stone could turn lead into gold, but its true power was far more profound.",
+	},
+	{
+		ID: 117,
+		Prompt: "The cybernetic warrior stood on the battlefield, the last line of defense against an invading alien force. His titanium armor was dented and scarred, a testament to past battles, but his optical sensors remained sharp. His internal processors, running at 4.2 GHz, were calculating the most effective strategy to defeat the enemy.",
+	},
+	{
+		ID: 118,
+		Prompt: "She discovered a hidden garden behind a crumbling brick wall. The plants inside were unlike anything she had ever seen, with glowing petals and leaves that changed color with the passing of the hours. It was a place of magic and tranquility: a secret sanctuary she had stumbled upon.",
+	},
+	{
+		ID: 119,
+		Prompt: "The legendary ghost ship, the Silent Whisper, sailed through the misty sea. Its sails were tattered and its wooden hull was rotting, but it moved with an eerie grace, its crew of spectral sailors going about their eternal duties. It was a ship of lost souls, forever bound to the vast ocean.",
+	},
+	{
+		ID: 120,
+		Prompt: "The time traveler adjusted the dials on his machine to 1066. A faint whirring sound filled the room as the temporal displacement engine began to power up. He was about to embark on his most dangerous mission yet: a journey to the distant past to witness a historical event and change the course of human history.",
+	},
+}
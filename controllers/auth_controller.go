@@ -5,8 +5,11 @@ import (
 	"os"
 	"time"
 
+	"github.com/Nitesh-04/realtime-racing/achievements"
 	"github.com/Nitesh-04/realtime-racing/config"
 	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/rating"
+	"github.com/Nitesh-04/realtime-racing/websockets"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -48,6 +51,11 @@ func validateRegistrationInput(name, email, username, password string) (string,
 	if len(password) < 6 {
 		return "password must be at least 6 characters long", false
 	}
+	// Bots race under this prefix and are never recorded, a user with it
+	// would be mistaken for one
+	if websockets.IsBotUsername(username) {
+		return fmt.Sprintf("usernames starting with %q are reserved", websockets.BotUsernamePrefix), false
+	}
 	return "", true
 }
 
@@ -186,8 +194,26 @@ func Me(c *fiber.Ctx) error{
 		})
 	}
 
+	userRating, err := rating.ForUser(db, user.ID)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load rating",
+		})
+	}
+
+	unlocked, err := achievements.ForUser(db, user.ID)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load achievements",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"user": user,
+		"user":         user,
+		"rating":       userRating,
+		"achievements": unlocked,
 	})
 
 }
\ No newline at end of file
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/daily"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// dailyResponse builds the body shared by today's and past challenges
+func dailyResponse(c *fiber.Ctx, challenge models.DailyChallenge) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid limit",
+			"details": "Limit must be between 1 and 100",
+		})
+	}
+
+	leaderboard, err := daily.Leaderboard(db, challenge.ID, limit)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch daily leaderboard",
+			"details": err.Error(),
+		})
+	}
+
+	attempts, err := daily.Attempts(db, challenge.ID, userUUID)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch attempts",
+			"details": err.Error(),
+		})
+	}
+
+	remaining := 0
+	if challenge.Day.Equal(daily.Today()) {
+		remaining = max(0, daily.MaxAttempts-len(attempts))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"challenge":          challenge,
+		"leaderboard":        leaderboard,
+		"attempts":           attempts,
+		"attempts_remaining": remaining,
+	})
+}
+
+// GetDaily returns today's challenge
+func GetDaily(c *fiber.Ctx) error {
+	challenge, err := daily.ForDay(config.DB, time.Now())
+
+	if err == daily.ErrNoPrompt {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "No daily challenge today",
+			"details": err.Error(),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch daily challenge",
+			"details": err.Error(),
+		})
+	}
+
+	return dailyResponse(c, challenge)
+}
+
+// GetDailyByDate returns the challenge of a past day, /api/daily/2025-01-31
+func GetDailyByDate(c *fiber.Ctx) error {
+	day, err := daily.ParseDay(c.Params("date"))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid date",
+			"details": "Date must be formatted as YYYY-MM-DD",
+		})
+	}
+
+	if day.After(daily.Today()) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No daily challenge for that day yet",
+		})
+	}
+
+	challenge, err := daily.ForDay(config.DB, day)
+
+	if err == daily.ErrNoChallenge || err == daily.ErrNoPrompt {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "No daily challenge for that day",
+			"details": err.Error(),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch daily challenge",
+			"details": err.Error(),
+		})
+	}
+
+	return dailyResponse(c, challenge)
+}
+
+// ListDailyChallenges pages through past challenges, newest first, ?page=1&limit=30
+func ListDailyChallenges(c *fiber.Ctx) error {
+	db := config.DB
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 30)
+	if limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid limit",
+			"details": "Limit must be between 1 and 100",
+		})
+	}
+
+	list, total, err := daily.List(db, page, limit)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch daily challenges",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"challenges": list,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"has_more":   int64(page*limit) < total,
+	})
+}
+
+// StartDailyAttempt begins a timed solo race at today's challenge
+func StartDailyAttempt(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	challenge, err := daily.ForDay(db, time.Now())
+
+	if err == daily.ErrNoPrompt {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "No daily challenge today",
+			"details": err.Error(),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch daily challenge",
+			"details": err.Error(),
+		})
+	}
+
+	attempt, err := daily.Start(db, challenge, userUUID)
+
+	if err == daily.ErrNoAttemptsLeft || err == daily.ErrNotToday {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to start attempt",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"attempt":   attempt,
+		"challenge": challenge,
+	})
+}
+
+// FinishDailyAttempt scores the {typed} text of an attempt
+func FinishDailyAttempt(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	attemptID, err := uuid.Parse(c.Params("id"))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid attempt ID",
+			"details": err.Error(),
+		})
+	}
+
+	var body struct {
+		Typed string `json:"typed"`
+	}
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	attempt, err := daily.Finish(db, attemptID, userUUID, body.Typed)
+
+	switch err {
+	case nil:
+	case daily.ErrAttemptNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case daily.ErrAttemptFinished, daily.ErrAttemptExpired:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   err.Error(),
+			"attempt": attempt,
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to finish attempt",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Attempt finished",
+		"attempt": attempt,
+	})
+}
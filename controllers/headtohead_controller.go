@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/headtohead"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	recentMatches = 10
+	maxRivals     = 10
+)
+
+// GetUserVersus returns the caller's record and latest races against another player
+func GetUserVersus(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	var opponent models.User
+	if err := db.Where("username = ?", c.Params("username")).First(&opponent).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "User not found",
+			"details": err.Error(),
+		})
+	}
+
+	if opponent.ID == userUUID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid opponent",
+			"details": "You can't compare yourself against yourself",
+		})
+	}
+
+	summary, err := headtohead.Summarize(db, userUUID, opponent.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load head-to-head record",
+			"details": err.Error(),
+		})
+	}
+
+	matches, err := headtohead.Recent(db, userUUID, opponent.ID, recentMatches)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load recent matches",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"opponent": opponent.Username,
+		"summary":  summary,
+		"matches":  matches,
+	})
+}
+
+// GetUserRivals lists the opponents the caller has raced the most
+func GetUserRivals(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	rivals, err := headtohead.Rivals(db, userUUID, maxRivals)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load rivals",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"rivals": rivals,
+	})
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/keystats"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// keyWindows maps the accepted ?window= values to days covered, 0 is all time
+var keyWindows = map[string]int{
+	"daily":    1,
+	"weekly":   7,
+	"monthly":  30,
+	"all_time": 0,
+}
+
+// GetUserKeys returns the user's key heatmap and slowest keys, ?window=monthly by default
+func GetUserKeys(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	window := c.Query("window", "monthly")
+	days, ok := keyWindows[window]
+
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid window",
+			"details": "Window must be one of daily, weekly, monthly or all_time",
+		})
+	}
+
+	var since time.Time
+	if days > 0 {
+		y, m, d := time.Now().UTC().Date()
+		since = time.Date(y, m, d-(days-1), 0, 0, 0, 0, time.UTC)
+	}
+
+	summary, err := keystats.Summarize(db, userUUID, since)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch key stats",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"window":          window,
+		"keys":            summary.Keys,
+		"most_missed":     summary.MostMissed,
+		"slowest_keys":    summary.SlowestKeys,
+		"slowest_bigrams": summary.SlowestBigrams,
+	})
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/leaderboard"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetLeaderboard returns the top players for a view and window, ?view=&window=&min_races=&recent=&limit=
+func GetLeaderboard(c *fiber.Ctx) error {
+	db := config.DB
+
+	view := c.Query("view", leaderboard.ViewBestWPM)
+	window := c.Query("window", leaderboard.PeriodAllTime)
+
+	if !slices.Contains(leaderboard.Views, view) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid view",
+			"details": "View must be one of best_wpm, avg_wpm or wins",
+		})
+	}
+
+	if !slices.Contains(leaderboard.Periods, window) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid window",
+			"details": "Window must be one of daily, weekly, monthly or all_time",
+		})
+	}
+
+	minRaces := c.QueryInt("min_races", 5)
+	if minRaces < 1 {
+		minRaces = 1
+	}
+
+	// Average WPM is taken over each player's latest races in the window
+	recent := c.QueryInt("recent", leaderboard.RecentRaces)
+	if recent < 1 || recent > leaderboard.MaxRecentRaces {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid recent",
+			"details": fmt.Sprintf("Recent must be between 1 and %d", leaderboard.MaxRecentRaces),
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid limit",
+			"details": "Limit must be between 1 and 100",
+		})
+	}
+
+	entries, err := leaderboard.Query(db, view, window, minRaces, recent, limit)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load leaderboard",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"view":        view,
+		"window":      window,
+		"min_races":   minRaces,
+		"recent":      recent,
+		"leaderboard": entries,
+	})
+}
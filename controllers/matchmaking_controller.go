@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/matchmaking"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// JoinMatchmaking puts the caller in the ranked queue, matches are pushed over /ws/lobby
+func JoinMatchmaking(c *fiber.Ctx) error {
+	db := config.DB
+
+	userId := c.Locals("userId").(string)
+
+	if userId == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"details": "User ID is required to join matchmaking",
+		})
+	}
+
+	var user models.User
+
+	if err := db.Where("id = ?", userId).First(&user).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "User not found",
+			"details": err.Error(),
+		})
+	}
+
+	wpm, err := matchmaking.Queue.Join(user)
+
+	if err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Failed to join matchmaking",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Joined matchmaking queue",
+		"wpm":     wpm,
+	})
+}
+
+func LeaveMatchmaking(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+
+	if userId == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"details": "User ID is required to leave matchmaking",
+		})
+	}
+
+	userUUID, err := uuid.Parse(userId)
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	if !matchmaking.Queue.Leave(userUUID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Not in queue",
+			"details": "You are not waiting in the matchmaking queue",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Left matchmaking queue",
+	})
+}
+
+func MatchmakingStatus(c *fiber.Ctx) error {
+	userId := c.Locals("userId").(string)
+
+	if userId == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Unauthorized",
+			"details": "User ID is required to check matchmaking",
+		})
+	}
+
+	userUUID, err := uuid.Parse(userId)
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	queued, match := matchmaking.Queue.Status(userUUID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"queued": queued,
+		"match":  match,
+	})
+}
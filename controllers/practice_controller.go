@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/constants"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/practice"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+	"github.com/Nitesh-04/realtime-racing/websockets"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CreatePracticeRoom opens a solo room drilling the user's weakest keys, body {duration, word_count}
+func CreatePracticeRoom(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	var body struct {
+		Duration  int `json:"duration"`
+		WordCount int `json:"word_count"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	if body.Duration == 0 {
+		body.Duration = models.DefaultRaceDuration
+	}
+
+	if !slices.Contains(models.RaceDurations, body.Duration) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid duration",
+			"details": fmt.Sprintf("Duration must be one of %v seconds", models.RaceDurations),
+		})
+	}
+
+	count := body.WordCount
+	if count == 0 {
+		count = prompts.WordsForDuration(body.Duration)
+	}
+
+	if count < 1 || count > prompts.MaxGeneratedWords {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid word count",
+			"details": fmt.Sprintf("Word count must be between 1 and %d", prompts.MaxGeneratedWords),
+		})
+	}
+
+	var user models.User
+
+	if err := db.First(&user, "id = ?", userUUID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "User not found",
+			"details": err.Error(),
+		})
+	}
+
+	spots, err := practice.WeakSpots(db, userUUID, time.Now().Add(-practice.Window))
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to analyze weak keys",
+			"details": err.Error(),
+		})
+	}
+
+	seed := rand.Int63()
+	text, err := practice.Generate(spots, count, seed)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to generate practice text",
+			"details": err.Error(),
+		})
+	}
+
+	var roomCode string
+
+	for {
+		roomCode = constants.GenerateRoomCode()
+
+		var existingRoom models.Room
+		result := db.Where("room_code = ?", roomCode).First(&existingRoom)
+		if result.RowsAffected == 0 {
+			break
+		}
+	}
+
+	room := models.Room{
+		RoomCode:   roomCode,
+		CreatorID:  userUUID,
+		RoomStatus: models.RoomStatusReady,
+		Prompt:     text,
+		Language:   "en",
+		Mode:       models.PromptModeText,
+		Duration:   body.Duration,
+		WordList:   practice.WordList,
+		WordSeed:   seed,
+		Practice:   true,
+	}
+
+	if err := db.Create(&room).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create room",
+			"details": err.Error(),
+		})
+	}
+
+	// Only the creator holds a ticket, so nobody else can connect
+	ticket, err := websockets.Hub.IssueTicket(roomCode, user.Username)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to issue join ticket",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Practice room created successfully",
+		"room":    room,
+		"ticket":  ticket,
+		"targets": spots,
+	})
+}
+
+// GetPracticeProgress reports how the user's weak spots moved week by week, ?weeks=8
+func GetPracticeProgress(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	weeks := c.QueryInt("weeks", 8)
+	if weeks < 1 || weeks > 26 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid weeks",
+			"details": "Weeks must be between 1 and 26",
+		})
+	}
+
+	spots, err := practice.WeakSpots(db, userUUID, time.Now().Add(-practice.Window))
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to analyze weak keys",
+			"details": err.Error(),
+		})
+	}
+
+	trends, err := practice.Progress(db, userUUID, spots, weeks)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch practice progress",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"weeks": weeks,
+		"spots": trends,
+	})
+}
@@ -0,0 +1,522 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// promptInput is the body for creating or updating a prompt, pointer fields are optional on update
+type promptInput struct {
+	Text       *string                  `json:"text"`
+	Source     *string                  `json:"source"`
+	Language   *string                  `json:"language"`
+	Tags       []string                 `json:"tags"`
+	Difficulty *models.PromptDifficulty `json:"difficulty"`
+	Active     *bool                    `json:"active"`
+
+	Mode         *models.PromptMode `json:"mode"`
+	CodeLanguage *string            `json:"code_language"`
+}
+
+// apply copies the provided fields onto the prompt and validates the result
+func (in promptInput) apply(prompt *models.Prompt) (string, bool) {
+	if in.Mode != nil {
+		prompt.Mode = *in.Mode
+	}
+	if in.CodeLanguage != nil {
+		prompt.CodeLanguage = strings.ToLower(strings.TrimSpace(*in.CodeLanguage))
+	}
+	if !prompt.Mode.Valid() {
+		return "mode must be text or code", false
+	}
+	if prompt.Mode == models.PromptModeText {
+		prompt.CodeLanguage = ""
+	} else if prompt.CodeLanguage == "" {
+		return "code prompts need a code_language", false
+	}
+
+	// Switching mode renormalizes the stored text as well
+	if in.Text != nil {
+		prompt.Text = prompts.NormalizeFor(prompt.Mode, *in.Text)
+	} else if in.Mode != nil {
+		prompt.Text = prompts.NormalizeFor(prompt.Mode, prompt.Text)
+	}
+	if in.Source != nil {
+		prompt.Source = strings.TrimSpace(*in.Source)
+	}
+	if in.Language != nil {
+		language, err := prompts.NormalizeLanguage(*in.Language)
+		if err != nil {
+			return err.Error(), false
+		}
+		prompt.Language = language
+	}
+	if in.Tags != nil {
+		prompt.Tags = in.Tags
+	}
+	textChanged := in.Text != nil || in.Mode != nil
+	if textChanged {
+		prompt.DifficultyScore = prompts.Analyze(prompt.Text).Score
+		prompt.Punctuated = prompts.IsPunctuated(prompt.Text)
+	}
+	// An explicit difficulty overrides the analyzer's bucket
+	if in.Difficulty != nil {
+		prompt.Difficulty = *in.Difficulty
+	} else if textChanged {
+		prompt.Difficulty = prompts.DifficultyFor(prompt.DifficultyScore)
+	}
+	if in.Active != nil {
+		prompt.Active = *in.Active
+	}
+	if prompt.Active && prompt.Status != models.PromptStatusApproved {
+		return "only approved prompts can be active", false
+	}
+
+	if prompts.Length(prompt.Text) < prompts.MinLength {
+		return fmt.Sprintf("text must be at least %d characters long", prompts.MinLength), false
+	}
+	if prompt.Language == "" {
+		return "language is required", false
+	}
+	if !prompt.Difficulty.Valid() {
+		return "difficulty must be one of easy, medium or hard", false
+	}
+
+	// Rejected prompts give up their text so it can be submitted again
+	if prompt.Status == models.PromptStatusRejected {
+		prompt.Hash = nil
+		return "", true
+	}
+	hash := prompts.HashFor(prompt.Mode, prompt.Text)
+	prompt.Hash = &hash
+	return "", true
+}
+
+// isDuplicatePrompt reports whether another non-rejected prompt has the same normalized text
+func isDuplicatePrompt(db *gorm.DB, prompt models.Prompt) (bool, error) {
+	if prompt.Hash == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&models.Prompt{}).
+		Where("hash = ? AND id <> ? AND status <> ?", *prompt.Hash, prompt.ID, models.PromptStatusRejected).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// loadPrompt fetches a prompt by its ID param and the status code to respond with on failure
+func loadPrompt(db *gorm.DB, id string) (models.Prompt, int, error) {
+	var prompt models.Prompt
+
+	promptID, err := uuid.Parse(id)
+
+	if err != nil {
+		return prompt, fiber.StatusBadRequest, fmt.Errorf("invalid prompt ID")
+	}
+
+	if err := db.First(&prompt, "id = ?", promptID).Error; err != nil {
+		return prompt, fiber.StatusNotFound, fmt.Errorf("prompt not found")
+	}
+
+	return prompt, fiber.StatusOK, nil
+}
+
+// filterPrompts applies the shared list filters, ?language=&difficulty=&mode=
+func filterPrompts(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+	if language := c.Query("language"); language != "" {
+		query = query.Where("language = ? OR language LIKE ?", language, language+"-%")
+	}
+	if difficulty := c.Query("difficulty"); difficulty != "" {
+		query = query.Where("difficulty = ?", difficulty)
+	}
+	if mode := c.Query("mode"); mode != "" {
+		query = query.Where("mode = ?", mode)
+	}
+	return query
+}
+
+// listPrompts responds with a page of the prompts matching the query
+func listPrompts(c *fiber.Ctx, query *gorm.DB) error {
+	limit := 50
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	// Reused for both the count and the page
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to count prompts",
+			"details": err.Error(),
+		})
+	}
+
+	var list []models.Prompt
+
+	if err := query.Order("created_at ASC").Limit(limit).Offset((page - 1) * limit).Find(&list).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch prompts",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"prompts": list,
+		"total":   total,
+		"page":    page,
+	})
+}
+
+// ListPrompts returns the library, ?active=&status=&language=&difficulty=&mode=
+func ListPrompts(c *fiber.Ctx) error {
+	db := config.DB
+
+	query := db.Model(&models.Prompt{})
+
+	if active := c.Query("active"); active != "" {
+		query = query.Where("active = ?", active == "true")
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	return listPrompts(c, filterPrompts(c, query))
+}
+
+// ListActivePrompts returns the prompts in rotation, ?language=&difficulty=&mode=
+func ListActivePrompts(c *fiber.Ctx) error {
+	db := config.DB
+
+	query := db.Model(&models.Prompt{}).Where("active = true")
+
+	return listPrompts(c, filterPrompts(c, query))
+}
+
+// ListPromptLanguages returns the languages with active prompts and their counts
+func ListPromptLanguages(c *fiber.Ctx) error {
+	db := config.DB
+
+	type languageCount struct {
+		Language string `json:"language"`
+		Prompts  int64  `json:"prompts"`
+	}
+
+	var languages []languageCount
+
+	err := db.Model(&models.Prompt{}).
+		Select("language, COUNT(*) AS prompts").
+		Where("active = true").
+		Group("language").
+		Order("prompts DESC, language ASC").
+		Scan(&languages).Error
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch languages",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"languages": languages,
+	})
+}
+
+// GetPrompt returns a prompt with its race stats and top 10, submitters also see their pending ones
+func GetPrompt(c *fiber.Ctx) error {
+	db := config.DB
+
+	userId := c.Locals("userId").(string)
+
+	prompt, status, err := loadPrompt(db, c.Params("id"))
+
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ownSubmission := prompt.SubmittedByID != nil && prompt.SubmittedByID.String() == userId
+
+	if prompt.Status != models.PromptStatusApproved && !ownSubmission {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "prompt not found",
+		})
+	}
+
+	var stat models.PromptStat
+
+	if err := db.Preload("RecordHolder").Where("prompt_id = ?", prompt.ID).Limit(1).Find(&stat).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch prompt stats",
+			"details": err.Error(),
+		})
+	}
+
+	top, err := prompts.Top(db, prompt.ID, 10)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch prompt records",
+			"details": err.Error(),
+		})
+	}
+
+	var recordHolder fiber.Map
+	if stat.RecordHolder != nil {
+		recordHolder = fiber.Map{
+			"id":       stat.RecordHolder.ID,
+			"username": stat.RecordHolder.Username,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"prompt": prompt,
+		"stats": fiber.Map{
+			"races":         stat.Races,
+			"avg_wpm":       stat.AvgWPM(),
+			"best_wpm":      stat.BestWPM,
+			"record_holder": recordHolder,
+			"record_set_at": stat.RecordSetAt,
+		},
+		"top": top,
+	})
+}
+
+func GetPromptAdmin(c *fiber.Ctx) error {
+	db := config.DB
+
+	prompt, status, err := loadPrompt(db, c.Params("id"))
+
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"prompt": prompt,
+	})
+}
+
+func CreatePrompt(c *fiber.Ctx) error {
+	db := config.DB
+
+	var body promptInput
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	prompt := models.Prompt{
+		Mode:     models.PromptModeText,
+		Language: "en",
+		Active:   true,
+		Status:   models.PromptStatusApproved,
+	}
+
+	if errMsg, valid := body.apply(&prompt); !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+
+	duplicate, err := isDuplicatePrompt(db, prompt)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to check for duplicates",
+			"details": err.Error(),
+		})
+	}
+
+	if duplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A prompt with the same text already exists",
+		})
+	}
+
+	if err := db.Create(&prompt).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create prompt",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Prompt created successfully",
+		"prompt":  prompt,
+	})
+}
+
+func UpdatePrompt(c *fiber.Ctx) error {
+	db := config.DB
+
+	prompt, status, err := loadPrompt(db, c.Params("id"))
+
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var body promptInput
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	if errMsg, valid := body.apply(&prompt); !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+
+	duplicate, err := isDuplicatePrompt(db, prompt)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to check for duplicates",
+			"details": err.Error(),
+		})
+	}
+
+	if duplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A prompt with the same text already exists",
+		})
+	}
+
+	if err := db.Save(&prompt).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update prompt",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Prompt updated successfully",
+		"prompt":  prompt,
+	})
+}
+
+// RetirePrompt takes a prompt out of rotation without deleting it
+func RetirePrompt(c *fiber.Ctx) error {
+	db := config.DB
+
+	prompt, status, err := loadPrompt(db, c.Params("id"))
+
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := db.Model(&prompt).Update("active", false).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to retire prompt",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Prompt retired successfully",
+		"prompt":  prompt,
+	})
+}
+
+// ImportPrompts loads prompts from the request body, ?format=json|csv|text
+func ImportPrompts(c *fiber.Ctx) error {
+	db := config.DB
+
+	format := c.Query("format", prompts.FormatJSON)
+
+	if !slices.Contains(prompts.Formats, format) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid format",
+			"details": "Format must be one of json, csv or text",
+		})
+	}
+
+	records, rowErrors, err := prompts.Parse(bytes.NewReader(c.Body()), format)
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Failed to parse prompts",
+			"details": err.Error(),
+		})
+	}
+
+	report, err := prompts.Import(db, records, rowErrors)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to import prompts",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Prompts imported",
+		"report":  report,
+	})
+}
+
+// ExportPrompts downloads the whole library, ?format=json|csv|text
+func ExportPrompts(c *fiber.Ctx) error {
+	db := config.DB
+
+	format := c.Query("format", prompts.FormatJSON)
+
+	contentTypes := map[string]string{
+		prompts.FormatJSON: fiber.MIMEApplicationJSONCharsetUTF8,
+		prompts.FormatCSV:  "text/csv; charset=utf-8",
+		prompts.FormatText: fiber.MIMETextPlainCharsetUTF8,
+	}
+	extensions := map[string]string{
+		prompts.FormatJSON: "json",
+		prompts.FormatCSV:  "csv",
+		prompts.FormatText: "txt",
+	}
+
+	contentType, ok := contentTypes[format]
+
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid format",
+			"details": "Format must be one of json, csv or text",
+		})
+	}
+
+	var buf bytes.Buffer
+
+	if err := prompts.Export(db, &buf, format); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to export prompts",
+			"details": err.Error(),
+		})
+	}
+
+	c.Attachment("prompts." + extensions[format])
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+	"github.com/Nitesh-04/realtime-racing/websockets"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxPendingSubmissions is how many prompts a user can have waiting for review
+const maxPendingSubmissions = 5
+
+// SubmitPrompt lets a user propose a passage, it stays out of rotation until approved
+func SubmitPrompt(c *fiber.Ctx) error {
+	db := config.DB
+
+	userId := c.Locals("userId").(string)
+
+	userUUID, err := uuid.Parse(userId)
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	var body promptInput
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	if body.Text == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "text is required",
+		})
+	}
+
+	// Difficulty is left to the analyzer and activation to the reviewer
+	body.Difficulty = nil
+	body.Active = nil
+
+	mode := models.PromptModeText
+	if body.Mode != nil {
+		mode = *body.Mode
+	}
+	text := prompts.Sanitize(mode, *body.Text)
+	body.Text = &text
+
+	prompt := models.Prompt{
+		Mode:          models.PromptModeText,
+		Language:      "en",
+		Active:        false,
+		Status:        models.PromptStatusPending,
+		SubmittedByID: &userUUID,
+	}
+
+	if errMsg, valid := body.apply(&prompt); !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+
+	if err := prompts.CheckLength(prompt.Text); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var pending int64
+
+	if err := db.Model(&models.Prompt{}).Where("submitted_by_id = ? AND status = ?", userUUID, models.PromptStatusPending).Count(&pending).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to count submissions",
+			"details": err.Error(),
+		})
+	}
+
+	if pending >= maxPendingSubmissions {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error":   "Too many pending submissions",
+			"details": "Wait for your pending prompts to be reviewed before submitting more",
+		})
+	}
+
+	duplicate, err := isDuplicatePrompt(db, prompt)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to check for duplicates",
+			"details": err.Error(),
+		})
+	}
+
+	if duplicate {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A prompt with the same text already exists",
+		})
+	}
+
+	if err := db.Create(&prompt).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to submit prompt",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Prompt submitted for review",
+		"prompt":  prompt,
+	})
+}
+
+// ListMyPrompts returns the prompts the user submitted with their review status
+func ListMyPrompts(c *fiber.Ctx) error {
+	db := config.DB
+
+	userId := c.Locals("userId").(string)
+
+	var list []models.Prompt
+
+	if err := db.Where("submitted_by_id = ?", userId).Order("created_at DESC").Find(&list).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch prompts",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"prompts": list,
+	})
+}
+
+// ApprovePrompt accepts a submission and puts it into rotation
+func ApprovePrompt(c *fiber.Ctx) error {
+	return reviewPrompt(c, models.PromptStatusApproved, "")
+}
+
+// RejectPrompt turns a submission down, the body may carry a {reason}
+func RejectPrompt(c *fiber.Ctx) error {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	return reviewPrompt(c, models.PromptStatusRejected, body.Reason)
+}
+
+func reviewPrompt(c *fiber.Ctx, status models.PromptStatus, reason string) error {
+	db := config.DB
+
+	prompt, code, err := loadPrompt(db, c.Params("id"))
+
+	if err != nil {
+		return c.Status(code).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if prompt.Status == status {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Prompt is already " + string(status),
+		})
+	}
+
+	now := time.Now()
+	prompt.Status = status
+	prompt.Active = status == models.PromptStatusApproved
+	prompt.ReviewedAt = &now
+	prompt.RejectionReason = reason
+
+	// A rejected prompt frees its text, taking it back out of rejection
+	// needs the text to still be free
+	if status == models.PromptStatusRejected {
+		prompt.Hash = nil
+	} else {
+		hash := prompts.HashFor(prompt.Mode, prompt.Text)
+		prompt.Hash = &hash
+
+		duplicate, err := isDuplicatePrompt(db, prompt)
+
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to check for duplicates",
+				"details": err.Error(),
+			})
+		}
+
+		if duplicate {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "A prompt with the same text already exists",
+			})
+		}
+	}
+
+	err = db.Model(&prompt).Select("status", "active", "reviewed_at", "rejection_reason", "hash").Updates(&prompt).Error
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to review prompt",
+			"details": err.Error(),
+		})
+	}
+
+	// Let the submitter know if they're online
+	if prompt.SubmittedByID != nil {
+		websockets.Lobby.NotifyUser(*prompt.SubmittedByID, "prompt_reviewed", fiber.Map{
+			"prompt_id": prompt.ID,
+			"status":    prompt.Status,
+			"reason":    prompt.RejectionReason,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Prompt " + string(status),
+		"prompt":  prompt,
+	})
+}
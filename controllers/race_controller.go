@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
 	"github.com/Nitesh-04/realtime-racing/config"
 	"github.com/Nitesh-04/realtime-racing/constants"
 	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/prompts"
 	"github.com/Nitesh-04/realtime-racing/websockets"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -59,18 +65,213 @@ func CreateRoom(c *fiber.Ctx) error {
 		})
 	}
 
-	prompt := constants.GetRandomPrompt() // Get a random prompt for the room
+	// Room options are optional, an empty body gets the defaults
+
+	var body struct {
+		Difficulty  models.PromptDifficulty `json:"difficulty"`
+		Punctuation bool                    `json:"punctuation"`
+
+		Mode         models.PromptMode `json:"mode"`
+		CodeLanguage string            `json:"code_language"`
+
+		// Language of the prompt, English when empty and any language for
+		// "any"
+		Language string `json:"language"`
+
+		// Race length in seconds
+		Duration int `json:"duration"`
+
+		// Generated rooms, word_count defaults to enough words for the duration
+		WordList  string `json:"word_list"`
+		WordCount int    `json:"word_count"`
+
+		// Private text for this race only, it doesn't enter the library
+		CustomText string `json:"custom_text"`
+	}
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	if body.Difficulty != "" && !body.Difficulty.Valid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid difficulty",
+			"details": "Difficulty must be one of easy, medium or hard",
+		})
+	}
+
+	if body.Mode == "" {
+		body.Mode = models.PromptModeText
+	}
+
+	if !body.Mode.Valid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid mode",
+			"details": "Mode must be text or code",
+		})
+	}
+
+	if body.Language == "" {
+		body.Language = "en"
+	}
+
+	if strings.EqualFold(strings.TrimSpace(body.Language), prompts.AnyLanguage) {
+		body.Language = prompts.AnyLanguage
+	} else {
+		language, err := prompts.NormalizeLanguage(body.Language)
+
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid language",
+				"details": err.Error(),
+			})
+		}
+
+		body.Language = language
+	}
+
+	if body.Duration == 0 {
+		body.Duration = models.DefaultRaceDuration
+	}
+
+	if !slices.Contains(models.RaceDurations, body.Duration) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid duration",
+			"details": fmt.Sprintf("Duration must be one of %v seconds", models.RaceDurations),
+		})
+	}
 
 	// Create a new room with the generated room code and the creator's user ID
 	// creator is the user who created the room
 
 	room := models.Room{
-		RoomCode:   roomCode,
-		CreatorID:  creatorUUID,
+		RoomCode:    roomCode,
+		CreatorID:   creatorUUID,
 		RoomStatus: models.RoomStatusWaiting,
-		Prompt:     prompt,
+		Mode:       body.Mode,
+		Duration:   body.Duration,
 	}
 
+	if body.CustomText != "" && body.WordList != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid room options",
+			"details": "Use either custom_text or word_list, not both",
+		})
+	}
+
+	if body.CustomText != "" {
+		text := prompts.Sanitize(body.Mode, body.CustomText)
+
+		if err := prompts.CheckLength(text); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid custom text",
+				"details": err.Error(),
+			})
+		}
+
+		room.Custom = true
+		room.Prompt = text
+		room.Punctuation = prompts.IsPunctuated(text)
+		room.Language = body.Language
+		if room.Language == prompts.AnyLanguage {
+			room.Language = "en"
+		}
+		if body.Mode == models.PromptModeCode {
+			room.CodeLanguage = strings.ToLower(strings.TrimSpace(body.CodeLanguage))
+		}
+	} else if body.WordList != "" {
+		// Generated rooms don't come from the library, the text is built from
+		// the word list and a seed stored on the room
+
+		if body.Mode != models.PromptModeText {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid mode",
+				"details": "Word lists can only be used in text mode",
+			})
+		}
+
+		// The word lists are English
+		if body.Language != "en" && body.Language != prompts.AnyLanguage {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid language",
+				"details": "Word lists are only available in English",
+			})
+		}
+
+		if !slices.Contains(prompts.WordLists, body.WordList) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid word list",
+				"details": fmt.Sprintf("Word list must be one of %v", prompts.WordLists),
+			})
+		}
+
+		count := body.WordCount
+		if count == 0 {
+			count = prompts.WordsForDuration(body.Duration)
+		}
+
+		seed := rand.Int63()
+		text, err := prompts.Generate(body.WordList, count, seed)
+
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Failed to generate prompt",
+				"details": err.Error(),
+			})
+		}
+
+		room.Prompt = text
+		room.Language = "en"
+		room.WordList = body.WordList
+		room.WordSeed = seed
+	} else {
+		filter := prompts.Filter{
+			Language:   body.Language,
+			Difficulty: body.Difficulty,
+			Mode:       body.Mode,
+		}
+
+		// The filter matches every language when its language is empty
+		if filter.Language == prompts.AnyLanguage {
+			filter.Language = ""
+		}
+
+		// Text rooms get plain lowercase text unless punctuation is on, code
+		// rooms can be narrowed down to one language
+		if body.Mode == models.PromptModeCode {
+			filter.CodeLanguage = strings.ToLower(body.CodeLanguage)
+		} else {
+			filter.Punctuated = &body.Punctuation
+		}
+
+		// Get a random prompt for the room
+		prompt, err := prompts.Random(db, filter)
+
+		if err == prompts.ErrNoPrompt {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   "No prompt available",
+				"details": err.Error(),
+			})
+		}
+
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to pick a prompt",
+				"details": err.Error(),
+			})
+		}
+
+		room.PromptID = &prompt.ID
+		room.Prompt = prompt.Text
+		room.Punctuation = filter.Punctuated != nil && *filter.Punctuated
+		room.CodeLanguage = prompt.CodeLanguage
+		room.Language = prompt.Language
+	}
 
 	if err := db.Create(&room).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -128,6 +329,13 @@ func JoinRoom(c *fiber.Ctx) error {
 		})
 	}
 
+	if room.Practice {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Practice rooms are solo",
+			"details": "Practice rooms can't be joined by other players",
+		})
+	}
+
 	opponentUUID, err := uuid.Parse(userId)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -370,7 +578,7 @@ func GameOver(c *fiber.Ctx) error {
 	// Prepare empty stats map or fill with real player stats if you have it
 	stats := map[string]websockets.PlayerStats{}
 
-	websockets.BroadcastGameOver(room.RoomCode, winnerName, stats, "Game Finished")
+	websockets.BroadcastGameOver(room.RoomCode, winnerName, stats, "Game Finished", nil)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Game over, winner updated successfully",
@@ -378,7 +586,49 @@ func GameOver(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateUserResult is gone, results are only written by the server when it times a race
+
 func UpdateUserResult(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusGone).JSON(fiber.Map{
+		"error":   "Endpoint removed",
+		"details": "Results are recorded by the server when the race ends",
+	})
+}
+
+func DeleteRoom(c *fiber.Ctx) error {
+	roomCode := c.Params("roomCode")
+
+	if roomCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Room code is required",
+			"details": "Please provide a valid room code to delete the room",
+		})
+	}
+
+	// Find the room by code
+	var room models.Room
+	if err := config.DB.Where("room_code = ?", roomCode).First(&room).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Room not found",
+			"details": err.Error(),
+		})
+	}
+
+	// Delete the room
+	if err := config.DB.Delete(&room).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete room",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Room deleted successfully",
+	})
+}
+
+// AddBot fills the empty slot of a room with a server-side bot opponent
+func AddBot(c *fiber.Ctx) error {
 	db := config.DB
 
 	userId := c.Locals("userId").(string)
@@ -386,7 +636,7 @@ func UpdateUserResult(c *fiber.Ctx) error {
 	if userId == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error":   "Unauthorized",
-			"details": "User ID is required to update user result",
+			"details": "User ID is required to add a bot",
 		})
 	}
 
@@ -395,16 +645,15 @@ func UpdateUserResult(c *fiber.Ctx) error {
 	if roomCode == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":   "Room code is required",
-			"details": "Please provide a valid room code to update user result",
+			"details": "Please provide a valid room code to add a bot",
 		})
 	}
 
 	var body struct {
-		WinnerID  string  `json:"winner_id"`
-		OpponentID string `json:"opponent_id"`
-		WPM       int     `json:"wpm"`
-		Accuracy  float64 `json:"accuracy"`
-		Error     float64 `json:"error"`
+		Skill       string  `json:"skill"`
+		WPM         int     `json:"wpm"`
+		Accuracy    float64 `json:"accuracy"`
+		Variability float64 `json:"variability"`
 	}
 
 	if err := c.BodyParser(&body); err != nil {
@@ -414,98 +663,77 @@ func UpdateUserResult(c *fiber.Ctx) error {
 		})
 	}
 
-	if  body.WinnerID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Winner ID is required",
-			"details": "Please provide a winner ID to update user result",
-		})
-	}
+	// Start from a skill preset and override with any explicit values
 
-	// Parse the user ID to uuid.UUID
-	userUUID, err := uuid.Parse(userId)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid user ID",
-			"details": err.Error(),
-		})
+	if body.Skill == "" {
+		body.Skill = "medium"
 	}
 
-	winnerUUID, err := uuid.Parse(body.WinnerID)
-
-	if err != nil {
+	bot, ok := websockets.BotSkills[body.Skill]
+	if !ok {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid winner ID",
-			"details": err.Error(),
+			"error":   "Invalid bot skill",
+			"details": "Skill must be one of easy, medium or hard",
 		})
 	}
+	if body.WPM != 0 {
+		bot.TargetWPM = body.WPM
+	}
+	if body.Accuracy != 0 {
+		bot.Accuracy = body.Accuracy
+	}
+	if body.Variability != 0 {
+		bot.Variability = body.Variability
+	}
 
-	opponentUUID, err := uuid.Parse(body.OpponentID)
+	var room models.Room
 
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Invalid opponent ID",
+	if err := db.Where("room_code = ?", roomCode).First(&room).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Room not found",
 			"details": err.Error(),
 		})
 	}
 
-	// Update the result for user
-
-	result := models.Results{
-		UserID:     userUUID,
-		OpponentID: opponentUUID,
-		Won:        winnerUUID == userUUID,
-		WPM:        body.WPM,
-		Accuracy:   body.Accuracy,
-		Error:      body.Error,
-	}
-
-	if err := db.Create(&result).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to update user result",
-			"details": err.Error(),
+	if room.CreatorID.String() != userId {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Only the room creator can add a bot",
+			"details": "You can only add bots to rooms you created",
 		})
 	}
 
-	db.Preload("Room").
-		Preload("User").
-		Preload("Opponent").
-		First(&result, "user_id = ?", userUUID)
-
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User result updated successfully",
-		"result":  result,
-	})
-}
-
-func DeleteRoom(c *fiber.Ctx) error {
-	roomCode := c.Params("roomCode")
-
-	if roomCode == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Room code is required",
-			"details": "Please provide a valid room code to delete the room",
+	if room.OpponentID != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Room is full",
+			"details": "The room already has an opponent",
 		})
 	}
 
-	// Find the room by code
-	var room models.Room
-	if err := config.DB.Where("room_code = ?", roomCode).First(&room).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   "Room not found",
+	botName, err := websockets.Hub.AddBot(roomCode, bot)
+
+	if err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Failed to add bot",
 			"details": err.Error(),
 		})
 	}
 
-	// Delete the room
-	if err := config.DB.Delete(&room).Error; err != nil {
+	room.RoomStatus = models.RoomStatusReady
+	room.Ranked = false
+
+	if err := db.Save(&room).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to delete room",
+			"error":   "Failed to update room status",
 			"details": err.Error(),
 		})
 	}
 
+	// Races against bots don't touch ratings, leaderboards or history
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Room deleted successfully",
+		"message":  "Bot added successfully",
+		"bot":      botName,
+		"config":   bot,
+		"ranked":   room.Ranked,
+		"recorded": false,
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/records"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetUserRecords returns the user's personal bests and streaks
+func GetUserRecords(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	durations, err := records.List(db, userUUID, models.RecordScopeDuration)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load personal bests",
+			"details": err.Error(),
+		})
+	}
+
+	byPrompt, err := records.List(db, userUUID, models.RecordScopePrompt)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load personal bests",
+			"details": err.Error(),
+		})
+	}
+
+	streak, err := records.Streak(db, userUUID, time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load streaks",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"durations": durations,
+		"prompts":   byPrompt,
+		"streaks":   streak,
+	})
+}
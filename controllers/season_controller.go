@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/seasons"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func ListSeasons(c *fiber.Ctx) error {
+	db := config.DB
+
+	var list []models.Season
+
+	if err := db.Order("starts_at DESC").Find(&list).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch seasons",
+			"details": err.Error(),
+		})
+	}
+
+	current, err := seasons.Current(db, time.Now())
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to fetch current season",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"seasons": list,
+		"current": current,
+	})
+}
+
+// GetSeasonLeaderboard returns live standings, or the archived ones once a season ended
+func GetSeasonLeaderboard(c *fiber.Ctx) error {
+	db := config.DB
+
+	seasonID, err := uuid.Parse(c.Params("id"))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid season ID",
+			"details": err.Error(),
+		})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid limit",
+			"details": "Limit must be between 1 and 100",
+		})
+	}
+
+	var season models.Season
+
+	if err := db.First(&season, "id = ?", seasonID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Season not found",
+			"details": err.Error(),
+		})
+	}
+
+	standings, err := seasons.Standings(db, season, limit)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load standings",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"season":      season,
+		"leaderboard": standings,
+	})
+}
+
+func CreateSeason(c *fiber.Ctx) error {
+	db := config.DB
+
+	var body struct {
+		Name     string    `json:"name"`
+		StartsAt time.Time `json:"starts_at"`
+		EndsAt   time.Time `json:"ends_at"`
+	}
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	if body.Name == "" || body.StartsAt.IsZero() || body.EndsAt.IsZero() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Name, starts_at and ends_at are required",
+			"details": "Dates must be RFC 3339 timestamps",
+		})
+	}
+
+	if !body.EndsAt.After(body.StartsAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid season dates",
+			"details": "ends_at must be after starts_at",
+		})
+	}
+
+	overlaps, err := seasons.Overlaps(db, body.StartsAt, body.EndsAt)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to check seasons",
+			"details": err.Error(),
+		})
+	}
+
+	if overlaps {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Season overlaps an existing season",
+			"details": "Seasons cannot run at the same time",
+		})
+	}
+
+	season := models.Season{
+		Name:     body.Name,
+		StartsAt: body.StartsAt,
+		EndsAt:   body.EndsAt,
+	}
+
+	if err := db.Create(&season).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create season",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Season created successfully",
+		"season":  season,
+	})
+}
+
+// ArchiveSeason snapshots the final standings of an ended season right away
+func ArchiveSeason(c *fiber.Ctx) error {
+	db := config.DB
+
+	seasonID, err := uuid.Parse(c.Params("id"))
+
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid season ID",
+			"details": err.Error(),
+		})
+	}
+
+	var season models.Season
+
+	if err := db.First(&season, "id = ?", seasonID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   "Season not found",
+			"details": err.Error(),
+		})
+	}
+
+	if err := seasons.Archive(db, &season); err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   "Failed to archive season",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Season archived successfully",
+		"season":  season,
+	})
+}
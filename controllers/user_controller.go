@@ -1,14 +1,29 @@
 package controllers
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/history"
 	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/percentile"
+	"github.com/Nitesh-04/realtime-racing/rating"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// GetUserResults pages through the user's results
+// Filters: ?result=won|lost&opponent=username&from=YYYY-MM-DD&to=YYYY-MM-DD
+// &min_wpm=&mode=text|code&prompt=id
+// Sorting and paging: ?sort=created_at|wpm|accuracy&order=asc|desc&limit=
+// &cursor=, where cursor is the next_cursor of the previous page
 
 func GetUserResults(c *fiber.Ctx) error {
 	db := config.DB
@@ -30,24 +45,132 @@ func GetUserResults(c *fiber.Ctx) error {
 		})
 	}
 
-	limit := 20
-	page := 1
-	if p := c.Query("page"); p != "" {
-		fmt.Sscanf(p, "%d", &page)
+	filter := history.Filter{UserID: userUUID}
+
+	switch c.Query("result") {
+	case "":
+	case "won", "lost":
+		won := c.Query("result") == "won"
+		filter.Won = &won
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid result filter",
+			"details": "Result must be won or lost",
+		})
+	}
+
+	if username := c.Query("opponent"); username != "" {
+		var opponent models.User
+		if err := db.Where("username = ?", username).First(&opponent).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Opponent not found",
+				"details": err.Error(),
+			})
+		}
+		filter.OpponentID = &opponent.ID
+	}
+
+	for _, bound := range []struct {
+		param  string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := c.Query(bound.param)
+		if s == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("Invalid %s date", bound.param),
+				"details": "Dates must be formatted as YYYY-MM-DD",
+			})
+		}
+		*bound.target = &day
+	}
+
+	if s := c.Query("min_wpm"); s != "" {
+		minWPM, err := strconv.Atoi(s)
+		if err != nil || minWPM < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid min_wpm",
+				"details": "min_wpm must be a non-negative integer",
+			})
+		}
+		filter.MinWPM = &minWPM
+	}
+
+	if s := c.Query("mode"); s != "" {
+		filter.Mode = models.PromptMode(s)
+		if !filter.Mode.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid mode",
+				"details": "Mode must be text or code",
+			})
+		}
+	}
+
+	if s := c.Query("prompt"); s != "" {
+		promptID, err := uuid.Parse(s)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid prompt ID",
+				"details": err.Error(),
+			})
+		}
+		filter.PromptID = &promptID
+	}
+
+	// Results used to be paged by number, page=1 is still the first page but
+	// later pages would silently repeat it
+	if s := c.Query("page"); s != "" && s != "1" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid page",
+			"details": "Results are paged with cursor, pass the next_cursor of the previous page",
+		})
+	}
+
+	page := history.Page{
+		Sort:  c.Query("sort", history.SortCreatedAt),
+		Order: c.Query("order", history.OrderDesc),
+		Size:  c.QueryInt("limit", history.DefaultPageSize),
+	}
+	if !slices.Contains(history.Sorts, page.Sort) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid sort",
+			"details": "Sort must be one of created_at, wpm or accuracy",
+		})
+	}
+	if page.Order != history.OrderAsc && page.Order != history.OrderDesc {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid order",
+			"details": "Order must be asc or desc",
+		})
+	}
+	if page.Size < 1 || page.Size > history.MaxPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit",
+			"details": fmt.Sprintf("Limit must be between 1 and %d", history.MaxPageSize),
+		})
+	}
+	if s := c.Query("cursor"); s != "" {
+		cursor, err := history.DecodeCursor(s)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid cursor",
+				"details": err.Error(),
+			})
+		}
+		page.After = &cursor
 	}
-	offset := (page - 1) * limit
 
-	var results []models.Results
-	
-	err = db.
-		Preload("User").
-		Preload("Opponent").
-		Where("user_id = ?", userUUID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&results).Error
+	listing, err := history.ListResults(db, filter, page)
 
+	if errors.Is(err, history.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid cursor",
+			"details": "The cursor doesn't belong to this sort order",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch user results",
@@ -61,30 +184,47 @@ func GetUserResults(c *fiber.Ctx) error {
 		User 	  models.User `json:"user"`
 		OpponentID *uuid.UUID `json:"opponent_id"`
 		Opponent   models.User `json:"opponent"`
+		PromptID   *uuid.UUID `json:"prompt_id"`
 		Won        bool      `json:"won"`
 		WPM        int   `json:"wpm"`
+		RawWPM     int   `json:"raw_wpm"`
 		Accuracy   float64   `json:"accuracy"`
 		Error      float64   `json:"error"`
+		CorrectedErrors int `json:"corrected_errors"`
+		Consistency float64 `json:"consistency"`
+		CreatedAt  time.Time `json:"created_at"`
 	}
 
-	var response []resultResponse
-	for _, result := range results {
+	response := make([]resultResponse, 0, len(listing.Results))
+	for _, result := range listing.Results {
 		resp := resultResponse{
 			ID:         result.ID,
 			UserID:     result.UserID,
 			User:       result.User,
 			OpponentID: &result.OpponentID,
 			Opponent:   result.Opponent,
+			PromptID:   result.PromptID,
 			Won:        result.Won,
 			WPM:        result.WPM,
+			RawWPM:     result.RawWPM,
 			Accuracy:   result.Accuracy,
 			Error:      result.Error,
+			CorrectedErrors: result.CorrectedErrors,
+			Consistency: result.Consistency,
+			CreatedAt:  result.CreatedAt,
 		}
 		response = append(response, resp)
 	}
 
+	var next *string
+	if listing.NextCursor != "" {
+		next = &listing.NextCursor
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"results": response,
+		"results":     response,
+		"total":       listing.Total,
+		"next_cursor": next,
 	})
 }
 
@@ -107,10 +247,34 @@ func GetUserStats(c *fiber.Ctx) error {
 		})
 	}
 
+	// Optionally restrict the stats to a single season
+	var seasonID *uuid.UUID
+	if s := c.Query("season"); s != "" {
+		parsed, err := uuid.Parse(s)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid season ID",
+				"details": err.Error(),
+			})
+		}
+		seasonID = &parsed
+	}
+
+	userResults := func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("user_id = ?", userUUID)
+		if seasonID != nil {
+			tx = tx.Where("season_id = ?", *seasonID)
+		}
+		return tx
+	}
+
 	type Stats struct {
 		AvgWPM      float64
+		AvgRawWPM   float64
 		AvgAccuracy float64
 		AvgError    float64
+		AvgCorrectedErrors float64
+		AvgConsistency     float64
 		TotalRaces  int64
 		Wins        int64
 		Losses      int64
@@ -120,8 +284,9 @@ func GetUserStats(c *fiber.Ctx) error {
 
 	// Calculate averages and counts
 	if err := db.Model(&models.Results{}).
-		Select("AVG(wpm) as avg_wpm, AVG(accuracy) as avg_accuracy, AVG(error) as avg_error, COUNT(*) as total_races").
-		Where("user_id = ?", userUUID).
+		Select("AVG(wpm) as avg_wpm, AVG(raw_wpm) as avg_raw_wpm, AVG(accuracy) as avg_accuracy, AVG(error) as avg_error, " +
+			"AVG(corrected_errors) as avg_corrected_errors, AVG(consistency) as avg_consistency, COUNT(*) as total_races").
+		Scopes(userResults).
 		Scan(&stats).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to calculate stats",
@@ -131,7 +296,8 @@ func GetUserStats(c *fiber.Ctx) error {
 
 	// Count wins
 	if err := db.Model(&models.Results{}).
-		Where("user_id = ? AND won = true", userUUID).
+		Scopes(userResults).
+		Where("won = true").
 		Count(&stats.Wins).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to count wins",
@@ -141,7 +307,8 @@ func GetUserStats(c *fiber.Ctx) error {
 
 	// Count losses (not first position)
 	if err := db.Model(&models.Results{}).
-		Where("user_id = ? AND won = false", userUUID).
+		Scopes(userResults).
+		Where("won = false").
 		Count(&stats.Losses).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to count losses",
@@ -152,14 +319,174 @@ func GetUserStats(c *fiber.Ctx) error {
 
 	stats.TotalRaces = stats.Wins + stats.Losses
 
+	userRating, err := rating.ForUser(db, userUUID)
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load rating",
+			"details": err.Error(),
+		})
+	}
+
+	standing, err := percentile.Current.ForUser(db, userUUID, time.Now())
+
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load percentile",
+			"details": err.Error(),
+		})
+	}
+
 	// return the stats
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"avg_wpm":      stats.AvgWPM,
 		"avg_accuracy": stats.AvgAccuracy,
 		"avg_error":    stats.AvgError,
+		"avg_raw_wpm":  stats.AvgRawWPM,
+		"avg_corrected_errors": stats.AvgCorrectedErrors,
+		"avg_consistency":      stats.AvgConsistency,
 		"total_races":  stats.TotalRaces,
 		"wins":         stats.Wins,
 		"losses":       stats.Losses,
+		"rating":       userRating,
+		"percentile":   standing,
+		"season_id":    seasonID,
+	})
+}
+// GetUserStatsHistory charts the user's results over time, ?bucket=&from=&to=&window=&season=
+func GetUserStatsHistory(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	bucket := c.Query("bucket", history.BucketDay)
+	if !slices.Contains(history.Buckets, bucket) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid bucket",
+			"details": "Bucket must be one of day, week or month",
+		})
+	}
+
+	to := time.Now()
+	if s := c.Query("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid to date",
+				"details": "Dates must be formatted as YYYY-MM-DD",
+			})
+		}
+	}
+
+	var from time.Time
+	switch bucket {
+	case history.BucketDay:
+		from = to.AddDate(0, 0, -29)
+	case history.BucketWeek:
+		from = to.AddDate(0, 0, -7*11)
+	case history.BucketMonth:
+		from = to.AddDate(0, -11, 0)
+	}
+	if s := c.Query("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid from date",
+				"details": "Dates must be formatted as YYYY-MM-DD",
+			})
+		}
+	}
+
+	window := c.QueryInt("window", history.DefaultWindow[bucket])
+	if window < 1 || window > 30 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid window",
+			"details": "Window must be between 1 and 30 buckets",
+		})
+	}
+
+	var seasonID *uuid.UUID
+	if s := c.Query("season"); s != "" {
+		parsed, err := uuid.Parse(s)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid season ID",
+				"details": err.Error(),
+			})
+		}
+		seasonID = &parsed
+	}
+
+	points, err := history.Series(db, history.Query{
+		UserID:   userUUID,
+		SeasonID: seasonID,
+		Bucket:   bucket,
+		From:     from,
+		To:       to,
+		Window:   window,
+	})
+	if errors.Is(err, history.ErrInvalidRange) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid range",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to build history",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"bucket":    bucket,
+		"window":    window,
+		"from":      history.Truncate(bucket, from),
+		"to":        history.Truncate(bucket, to),
+		"season_id": seasonID,
+		"points":    points,
+	})
+}
+
+// ExportUserResults streams the user's whole race history as a download, ?format=csv|json
+func ExportUserResults(c *fiber.Ctx) error {
+	db := config.DB
+
+	userUUID, err := uuid.Parse(c.Locals("userId").(string))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid user ID",
+			"details": err.Error(),
+		})
+	}
+
+	format := c.Query("format", history.FormatCSV)
+	if !slices.Contains(history.Formats, format) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid format",
+			"details": "Format must be csv or json",
+		})
+	}
+
+	filename := fmt.Sprintf("results-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+	c.Attachment(filename)
+	if format == history.FormatCSV {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	}
+
+	// Headers are already sent once streaming starts, so failures can only
+	// cut the download short
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := history.Export(db, userUUID, w, format); err != nil {
+			log.Printf("Failed to export results for %s: %v", userUUID, err)
+		}
 	})
-}
\ No newline at end of file
+	return nil
+}
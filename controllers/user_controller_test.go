@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGetUserResultsPage(t *testing.T) {
+	app := fiber.New()
+	app.Get("/results", func(c *fiber.Ctx) error {
+		c.Locals("userId", "6f1c2b9e-7c1a-4a4e-9a36-3c1f4d2b8e10")
+		return c.Next()
+	}, GetUserResults)
+
+	// Every case stops before the database, a bad cursor shows the page
+	// parameter itself got through
+	tests := []struct {
+		name      string
+		query     string
+		wantError string
+	}{
+		{"first page", "?page=1&cursor=bogus", "Invalid cursor"},
+		{"first page with a limit", "?page=1&limit=0", "Invalid limit"},
+		{"later page", "?page=2", "Invalid page"},
+		{"not a number", "?page=next", "Invalid page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/results"+tt.query, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+			}
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", body.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package daily
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/scoring"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// MaxAttempts is how many times a user can race each daily challenge
+	MaxAttempts = 3
+
+	// AttemptTimeout is how long a started attempt can take to be finished
+	AttemptTimeout = 10 * time.Minute
+
+	dayLayout = "2006-01-02"
+)
+
+var (
+	ErrNoChallenge     = fmt.Errorf("no daily challenge for that day")
+	ErrNoPrompt        = fmt.Errorf("no prompt available for the daily challenge")
+	ErrNoAttemptsLeft  = fmt.Errorf("no attempts left for today's challenge")
+	ErrNotToday        = fmt.Errorf("only today's challenge can be raced")
+	ErrAttemptFinished = fmt.Errorf("attempt is already finished")
+	ErrAttemptExpired  = fmt.Errorf("attempt took too long to finish")
+	ErrAttemptNotFound = fmt.Errorf("attempt not found")
+)
+
+type Entry struct {
+	Rank       int       `json:"rank"`
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	WPM        int       `json:"wpm"`
+	Accuracy   float64   `json:"accuracy"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Today is midnight UTC of the current day
+func Today() time.Time {
+	return DayOf(time.Now())
+}
+
+// DayOf truncates t to midnight UTC
+func DayOf(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDay reads a YYYY-MM-DD date
+func ParseDay(s string) (time.Time, error) {
+	return time.Parse(dayLayout, s)
+}
+
+// ForDay returns the challenge of a day, creating it for today or any past day since the first
+func ForDay(db *gorm.DB, day time.Time) (models.DailyChallenge, error) {
+	day = DayOf(day)
+
+	var challenge models.DailyChallenge
+	if err := db.Where("day = ?", day).Limit(1).Find(&challenge).Error; err != nil {
+		return challenge, err
+	}
+	if challenge.ID != uuid.Nil {
+		return challenge, nil
+	}
+	if day.After(Today()) {
+		return challenge, ErrNoChallenge
+	}
+	if !day.Equal(Today()) {
+		first, ok, err := First(db)
+		if err != nil {
+			return challenge, err
+		}
+		if !ok || day.Before(first) {
+			return challenge, ErrNoChallenge
+		}
+	}
+
+	return create(db, day)
+}
+
+// create picks the prompt for a day that has no challenge yet
+func create(db *gorm.DB, day time.Time) (models.DailyChallenge, error) {
+	prompt, err := pick(db, day)
+	if err != nil {
+		return models.DailyChallenge{}, err
+	}
+
+	challenge := models.DailyChallenge{
+		Day:      day,
+		PromptID: &prompt.ID,
+		Prompt:   prompt.Text,
+		Language: prompt.Language,
+	}
+
+	// Concurrent first requests of the day race to insert, the loser reads
+	// the winner's row
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "day"}},
+		DoNothing: true,
+	}).Create(&challenge).Error
+	if err != nil {
+		return challenge, err
+	}
+
+	err = db.Where("day = ?", day).First(&challenge).Error
+	return challenge, err
+}
+
+// First is the day of the earliest challenge, ok is false when there is none
+func First(db *gorm.DB) (time.Time, bool, error) {
+	var first models.DailyChallenge
+	if err := db.Order("day ASC").Limit(1).Find(&first).Error; err != nil {
+		return time.Time{}, false, err
+	}
+	if first.ID == uuid.Nil {
+		return time.Time{}, false, nil
+	}
+	return DayOf(first.Day), true, nil
+}
+
+// List returns a page of challenges, newest first, and the total number of days
+func List(db *gorm.DB, page, limit int) ([]models.DailyChallenge, int64, error) {
+	today := Today()
+	if _, err := ForDay(db, today); err != nil && err != ErrNoPrompt {
+		return nil, 0, err
+	}
+
+	first, ok, err := First(db)
+	if err != nil || !ok {
+		return nil, 0, err
+	}
+	total := int64(today.Sub(first)/(24*time.Hour)) + 1
+
+	newest := today.AddDate(0, 0, -(page-1)*limit)
+	oldest := newest.AddDate(0, 0, -(limit - 1))
+	if oldest.Before(first) {
+		oldest = first
+	}
+	if newest.Before(oldest) {
+		return []models.DailyChallenge{}, total, nil
+	}
+
+	var existing []models.DailyChallenge
+	if err := db.Where("day BETWEEN ? AND ?", oldest, newest).Find(&existing).Error; err != nil {
+		return nil, total, err
+	}
+	byDay := make(map[time.Time]models.DailyChallenge, len(existing))
+	for _, c := range existing {
+		byDay[DayOf(c.Day)] = c
+	}
+
+	list := make([]models.DailyChallenge, 0, limit)
+	for day := newest; !day.Before(oldest); day = day.AddDate(0, 0, -1) {
+		challenge, ok := byDay[day]
+		if !ok {
+			challenge, err = create(db, day)
+			if err != nil {
+				return nil, total, err
+			}
+		}
+		list = append(list, challenge)
+	}
+	return list, total, nil
+}
+
+// pick chooses the day's English prompt from a hash of the date so every instance agrees
+func pick(db *gorm.DB, day time.Time) (models.Prompt, error) {
+	var ids []uuid.UUID
+	err := db.Model(&models.Prompt{}).
+		Where("active = true AND mode = ? AND language = ? AND punctuated = false", models.PromptModeText, "en").
+		Order("created_at ASC, id ASC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return models.Prompt{}, err
+	}
+	if len(ids) == 0 {
+		return models.Prompt{}, ErrNoPrompt
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(day.Format(dayLayout)))
+
+	var prompt models.Prompt
+	err = db.First(&prompt, "id = ?", ids[h.Sum64()%uint64(len(ids))]).Error
+	return prompt, err
+}
+
+// Attempts lists the user's attempts at a challenge, oldest first
+func Attempts(db *gorm.DB, challengeID, userID uuid.UUID) ([]models.DailyAttempt, error) {
+	var attempts []models.DailyAttempt
+	err := db.Where("challenge_id = ? AND user_id = ?", challengeID, userID).
+		Order("started_at ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// Start opens a new attempt at today's challenge if the user has any left
+func Start(db *gorm.DB, challenge models.DailyChallenge, userID uuid.UUID) (models.DailyAttempt, error) {
+	attempt := models.DailyAttempt{
+		ChallengeID: challenge.ID,
+		UserID:      userID,
+		StartedAt:   time.Now(),
+	}
+
+	if !DayOf(challenge.Day).Equal(Today()) {
+		return attempt, ErrNotToday
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Serialize the user's starts so two requests can't both take the
+		// last attempt
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.DailyAttempt{}).
+			Where("challenge_id = ? AND user_id = ?", challenge.ID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= MaxAttempts {
+			return ErrNoAttemptsLeft
+		}
+
+		return tx.Create(&attempt).Error
+	})
+
+	return attempt, err
+}
+
+// Finish scores an attempt's typed text, timed by the server from the start of the attempt
+func Finish(db *gorm.DB, attemptID, userID uuid.UUID, typed string) (models.DailyAttempt, error) {
+	var attempt models.DailyAttempt
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Challenge").
+			Where("id = ? AND user_id = ?", attemptID, userID).
+			Limit(1).
+			Find(&attempt).Error
+		if err != nil {
+			return err
+		}
+		if attempt.ID == uuid.Nil {
+			return ErrAttemptNotFound
+		}
+		if attempt.FinishedAt != nil {
+			return ErrAttemptFinished
+		}
+
+		now := time.Now()
+		elapsed := now.Sub(attempt.StartedAt)
+		attempt.FinishedAt = &now
+
+		// Expired attempts are closed without a score
+		if elapsed > AttemptTimeout {
+			return tx.Model(&attempt).Update("finished_at", now).Error
+		}
+
+		result := scoring.Score(attempt.Challenge.Prompt, typed, elapsed, scoring.RuleFor(attempt.Challenge.Language))
+		attempt.WPM = result.WPM
+		attempt.RawWPM = result.RawWPM
+		attempt.Accuracy = result.Accuracy
+		attempt.Errors = result.Errors
+		attempt.Progress = result.Progress
+		attempt.Completed = result.Progress >= 100
+
+		return tx.Model(&attempt).
+			Select("finished_at", "wpm", "raw_wpm", "accuracy", "errors", "progress", "completed").
+			Updates(&attempt).Error
+	})
+	if err != nil {
+		return attempt, err
+	}
+
+	if attempt.FinishedAt != nil && attempt.FinishedAt.Sub(attempt.StartedAt) > AttemptTimeout {
+		return attempt, ErrAttemptExpired
+	}
+	return attempt, nil
+}
+
+// Leaderboard ranks each user's best completed attempt at a challenge
+func Leaderboard(db *gorm.DB, challengeID uuid.UUID, limit int) ([]Entry, error) {
+	best := db.Table("daily_attempts").
+		Select("DISTINCT ON (daily_attempts.user_id) daily_attempts.user_id, users.username, "+
+			"daily_attempts.wpm, daily_attempts.accuracy, daily_attempts.finished_at").
+		Joins("JOIN users ON users.id = daily_attempts.user_id").
+		Where("daily_attempts.challenge_id = ? AND daily_attempts.completed = true", challengeID).
+		Order("daily_attempts.user_id, daily_attempts.wpm DESC, daily_attempts.accuracy DESC, daily_attempts.finished_at ASC")
+
+	var entries []Entry
+	err := db.Table("(?) AS best", best).
+		Order("wpm DESC, accuracy DESC, finished_at ASC").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries, nil
+}
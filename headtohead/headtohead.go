@@ -0,0 +1,107 @@
+package headtohead
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Each race writes one result per player, so the caller's rows give the
+// record and the opponent's rows from the same room give their side
+
+// Summary is the overall record between a player and one opponent
+type Summary struct {
+	Races          int     `json:"races"`
+	Wins           int     `json:"wins"`
+	Losses         int     `json:"losses"`
+	AvgWPM         float64 `json:"avg_wpm"`
+	OpponentAvgWPM float64 `json:"opponent_avg_wpm"`
+}
+
+// Match is one race between the two players, from the player's side
+type Match struct {
+	ResultID         uuid.UUID  `json:"result_id"`
+	RoomID           *uuid.UUID `json:"room_id"`
+	Won              bool       `json:"won"`
+	WPM              int        `json:"wpm"`
+	Accuracy         float64    `json:"accuracy"`
+	OpponentWPM      *int       `json:"opponent_wpm"`
+	OpponentAccuracy *float64   `json:"opponent_accuracy"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Rival is an opponent the player raced often
+type Rival struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	Races      int       `json:"races"`
+	Wins       int       `json:"wins"`
+	Losses     int       `json:"losses"`
+	AvgWPM     float64   `json:"avg_wpm"`
+	LastRaceAt time.Time `json:"last_race_at"`
+}
+
+// Summarize returns the record of userID against opponentID
+func Summarize(db *gorm.DB, userID, opponentID uuid.UUID) (Summary, error) {
+	var summary Summary
+	err := db.Table("results").
+		Select("COUNT(*) AS races, "+
+			"COALESCE(SUM(CASE WHEN won THEN 1 ELSE 0 END), 0) AS wins, "+
+			"COALESCE(AVG(wpm), 0) AS avg_wpm").
+		Where("user_id = ? AND opponent_id = ?", userID, opponentID).
+		Scan(&summary).Error
+	if err != nil {
+		return summary, err
+	}
+	summary.Losses = summary.Races - summary.Wins
+
+	var opponentAvg *float64
+	err = db.Table("results").
+		Select("AVG(wpm)").
+		Where("user_id = ? AND opponent_id = ?", opponentID, userID).
+		Scan(&opponentAvg).Error
+	if err != nil {
+		return summary, err
+	}
+	if opponentAvg != nil {
+		summary.OpponentAvgWPM = *opponentAvg
+	}
+	return summary, nil
+}
+
+// Recent lists the latest races between the two players, newest first
+func Recent(db *gorm.DB, userID, opponentID uuid.UUID, limit int) ([]Match, error) {
+	var matches []Match
+	err := db.Table("results AS r").
+		Select("r.id AS result_id, r.room_id, r.won, r.wpm, r.accuracy, "+
+			"o.wpm AS opponent_wpm, o.accuracy AS opponent_accuracy, r.created_at").
+		Joins("LEFT JOIN results AS o ON o.room_id = r.room_id AND o.user_id = r.opponent_id").
+		Where("r.user_id = ? AND r.opponent_id = ?", userID, opponentID).
+		Order("r.created_at DESC").
+		Limit(limit).
+		Scan(&matches).Error
+	return matches, err
+}
+
+// Rivals returns the opponents the player raced most, ties going to the most recent
+func Rivals(db *gorm.DB, userID uuid.UUID, limit int) ([]Rival, error) {
+	var rivals []Rival
+	err := db.Table("results").
+		Select("results.opponent_id AS user_id, users.username, COUNT(*) AS races, "+
+			"SUM(CASE WHEN results.won THEN 1 ELSE 0 END) AS wins, "+
+			"AVG(results.wpm) AS avg_wpm, MAX(results.created_at) AS last_race_at").
+		Joins("JOIN users ON users.id = results.opponent_id").
+		Where("results.user_id = ?", userID).
+		Group("results.opponent_id, users.username").
+		Order("races DESC, last_race_at DESC").
+		Limit(limit).
+		Scan(&rivals).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range rivals {
+		rivals[i].Losses = rivals[i].Races - rivals[i].Wins
+	}
+	return rivals, nil
+}
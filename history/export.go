@@ -0,0 +1,175 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var Formats = []string{FormatCSV, FormatJSON}
+
+// flushEvery is how many rows are written between flushes of the output
+const flushEvery = 500
+
+// Row is one exported result, the prompt is empty for custom and generated races
+type Row struct {
+	ID              uuid.UUID  `json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Opponent        string     `json:"opponent"`
+	Won             bool       `json:"won"`
+	PromptID        *uuid.UUID `json:"prompt_id"`
+	Prompt          string     `json:"prompt"`
+	Mode            string     `json:"mode"`
+	Duration        int        `json:"duration"`
+	Language        string     `json:"language"`
+	WPM             int        `json:"wpm"`
+	RawWPM          int        `json:"raw_wpm"`
+	Accuracy        float64    `json:"accuracy"`
+	Error           float64    `json:"errors"`
+	CorrectedErrors int        `json:"corrected_errors"`
+	Consistency     float64    `json:"consistency"`
+	SeasonID        *uuid.UUID `json:"season_id"`
+}
+
+var exportHeader = []string{
+	"id", "created_at", "opponent", "won", "prompt_id", "prompt", "mode", "duration", "language",
+	"wpm", "raw_wpm", "accuracy", "errors", "corrected_errors", "consistency", "season_id",
+}
+
+func (r Row) record() []string {
+	optional := func(id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return id.String()
+	}
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return []string{
+		r.ID.String(),
+		r.CreatedAt.UTC().Format(time.RFC3339),
+		r.Opponent,
+		strconv.FormatBool(r.Won),
+		optional(r.PromptID),
+		r.Prompt,
+		r.Mode,
+		strconv.Itoa(r.Duration),
+		r.Language,
+		strconv.Itoa(r.WPM),
+		strconv.Itoa(r.RawWPM),
+		float(r.Accuracy),
+		float(r.Error),
+		strconv.Itoa(r.CorrectedErrors),
+		float(r.Consistency),
+		optional(r.SeasonID),
+	}
+}
+
+// flush pushes buffered output to the client when the writer supports it
+func flush(w io.Writer) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Export streams every result of the user, oldest first, one row at a time
+func Export(db *gorm.DB, userID uuid.UUID, w io.Writer, format string) error {
+	if format != FormatCSV && format != FormatJSON {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	rows, err := db.Table("results AS r").
+		Select("r.id, r.created_at, o.username AS opponent, r.won, r.prompt_id, "+
+			"COALESCE(p.text, '') AS prompt, r.mode, r.duration, COALESCE(p.language, '') AS language, "+
+			"r.wpm, r.raw_wpm, r.accuracy, r.error, r.corrected_errors, r.consistency, r.season_id").
+		Joins("LEFT JOIN users AS o ON o.id = r.opponent_id").
+		Joins("LEFT JOIN prompts AS p ON p.id = r.prompt_id").
+		Where("r.user_id = ?", userID).
+		Order("r.created_at ASC, r.id ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var writer *csv.Writer
+	if format == FormatCSV {
+		writer = csv.NewWriter(w)
+		if err := writer.Write(exportHeader); err != nil {
+			return err
+		}
+	} else if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	n := 0
+	for rows.Next() {
+		var row Row
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		if format == FormatCSV {
+			if err := writer.Write(row.record()); err != nil {
+				return err
+			}
+		} else {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			sep := ",\n"
+			if n == 0 {
+				sep = "\n"
+			}
+			if _, err := io.WriteString(w, sep+string(data)); err != nil {
+				return err
+			}
+		}
+
+		n++
+		if n%flushEvery == 0 {
+			if writer != nil {
+				writer.Flush()
+				if err := writer.Error(); err != nil {
+					return err
+				}
+			}
+			if err := flush(w); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if writer != nil {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+	} else if _, err := io.WriteString(w, "\n]\n"); err != nil {
+		return err
+	}
+	return flush(w)
+}
+
+// Backfill copies the mode of older results from their library prompt
+func Backfill(db *gorm.DB) error {
+	return db.Exec(`UPDATE results SET mode = prompts.mode FROM prompts
+		WHERE results.prompt_id = prompts.id AND results.duration = 0 AND results.mode <> prompts.mode`).Error
+}
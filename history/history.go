@@ -0,0 +1,170 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+
+	// MaxBuckets caps how long a requested range can be
+	MaxBuckets = 366
+)
+
+var Buckets = []string{BucketDay, BucketWeek, BucketMonth}
+
+var ErrInvalidRange = errors.New("invalid range")
+
+// DefaultWindow is the moving average window used when none is asked for
+var DefaultWindow = map[string]int{
+	BucketDay:   7,
+	BucketWeek:  4,
+	BucketMonth: 3,
+}
+
+// Point is one bucket of a user's results, moving averages are nil until the window holds a race
+type Point struct {
+	Start          time.Time `json:"start"`
+	Races          int       `json:"races"`
+	AvgWPM         float64   `json:"avg_wpm"`
+	BestWPM        int       `json:"best_wpm"`
+	AvgAccuracy    float64   `json:"avg_accuracy"`
+	AvgRawWPM      float64   `json:"avg_raw_wpm"`
+	AvgConsistency float64   `json:"avg_consistency"`
+
+	MovingAvgWPM      *float64 `json:"moving_avg_wpm"`
+	MovingAvgAccuracy *float64 `json:"moving_avg_accuracy"`
+}
+
+// Query selects the results a series is built from
+type Query struct {
+	UserID   uuid.UUID
+	SeasonID *uuid.UUID
+	Bucket   string
+	From     time.Time // Inclusive, truncated to the bucket
+	To       time.Time // Inclusive, truncated to the bucket
+	Window   int
+}
+
+// Truncate returns the start of the UTC bucket holding t
+func Truncate(bucket string, t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	switch bucket {
+	case BucketWeek:
+		// Weeks start on Monday, like Postgres' date_trunc
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// Next returns the start of the bucket after start
+func Next(bucket string, start time.Time) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Series buckets the user's results from q.From to q.To, empty buckets included
+func Series(db *gorm.DB, q Query) ([]Point, error) {
+	from := Truncate(q.Bucket, q.From)
+	to := Truncate(q.Bucket, q.To)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: it ends before it starts", ErrInvalidRange)
+	}
+
+	n := 0
+	for t := from; !t.After(to); t = Next(q.Bucket, t) {
+		n++
+		if n > MaxBuckets {
+			return nil, fmt.Errorf("%w: it covers more than %d buckets", ErrInvalidRange, MaxBuckets)
+		}
+	}
+
+	var rows []struct {
+		Start          time.Time
+		Races          int
+		AvgWPM         float64
+		BestWPM        int
+		AvgAccuracy    float64
+		AvgRawWPM      float64
+		AvgConsistency float64
+	}
+
+	trunc := fmt.Sprintf("date_trunc('%s', created_at AT TIME ZONE 'UTC')", q.Bucket)
+	query := db.Table("results").
+		Select(trunc+" AS start, COUNT(*) AS races, AVG(wpm) AS avg_wpm, MAX(wpm) AS best_wpm, AVG(accuracy) AS avg_accuracy, "+
+			"AVG(raw_wpm) AS avg_raw_wpm, AVG(consistency) AS avg_consistency").
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", q.UserID, from, Next(q.Bucket, to)).
+		Group("start").
+		Order("start ASC")
+	if q.SeasonID != nil {
+		query = query.Where("season_id = ?", *q.SeasonID)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byStart := make(map[time.Time]int, len(rows))
+	for i, r := range rows {
+		byStart[r.Start.UTC()] = i
+	}
+
+	points := make([]Point, 0, n)
+	for t := from; !t.After(to); t = Next(q.Bucket, t) {
+		p := Point{Start: t}
+		if i, ok := byStart[t]; ok {
+			r := rows[i]
+			p.Races = r.Races
+			p.AvgWPM = round2(r.AvgWPM)
+			p.BestWPM = r.BestWPM
+			p.AvgAccuracy = round2(r.AvgAccuracy)
+			p.AvgRawWPM = round2(r.AvgRawWPM)
+			p.AvgConsistency = round2(r.AvgConsistency)
+		}
+		points = append(points, p)
+	}
+
+	movingAverages(points, q.Window)
+	return points, nil
+}
+
+func movingAverages(points []Point, window int) {
+	if window < 1 {
+		window = 1
+	}
+	for i := range points {
+		var races int
+		var wpm, accuracy float64
+		for j := max(0, i-window+1); j <= i; j++ {
+			races += points[j].Races
+			wpm += points[j].AvgWPM * float64(points[j].Races)
+			accuracy += points[j].AvgAccuracy * float64(points[j].Races)
+		}
+		if races == 0 {
+			continue
+		}
+		avgWPM := round2(wpm / float64(races))
+		avgAccuracy := round2(accuracy / float64(races))
+		points[i].MovingAvgWPM = &avgWPM
+		points[i].MovingAvgAccuracy = &avgAccuracy
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,115 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncate(t *testing.T) {
+	// A Wednesday evening in New York is already Thursday in UTC
+	ny := time.FixedZone("EST", -5*3600)
+
+	tests := []struct {
+		name   string
+		bucket string
+		t      time.Time
+		want   time.Time
+	}{
+		{"day", BucketDay, time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC), time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{"day in UTC", BucketDay, time.Date(2025, 3, 12, 22, 0, 0, 0, ny), time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{"week from Wednesday", BucketWeek, time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"week from Monday", BucketWeek, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"week from Sunday", BucketWeek, time.Date(2025, 3, 16, 23, 59, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"week across a month", BucketWeek, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2025, 2, 24, 0, 0, 0, 0, time.UTC)},
+		{"month", BucketMonth, time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.bucket, tt.t); !got.Equal(tt.want) {
+				t.Errorf("Truncate(%s, %v) = %v, want %v", tt.bucket, tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		bucket string
+		start  time.Time
+		want   time.Time
+	}{
+		{BucketDay, time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{BucketWeek, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{BucketMonth, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bucket, func(t *testing.T) {
+			if got := Next(tt.bucket, tt.start); !got.Equal(tt.want) {
+				t.Errorf("Next(%s, %v) = %v, want %v", tt.bucket, tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovingAverages(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []Point
+		window  int
+		wantWPM []float64 // -1 for no average
+		wantAcc []float64
+	}{
+		{
+			name:    "weighted by races",
+			points:  []Point{{Races: 1, AvgWPM: 60, AvgAccuracy: 90}, {Races: 3, AvgWPM: 80, AvgAccuracy: 98}},
+			window:  2,
+			wantWPM: []float64{60, 75},
+			wantAcc: []float64{90, 96},
+		},
+		{
+			name:    "window slides",
+			points:  []Point{{Races: 1, AvgWPM: 50, AvgAccuracy: 90}, {Races: 1, AvgWPM: 70, AvgAccuracy: 94}, {Races: 1, AvgWPM: 90, AvgAccuracy: 98}},
+			window:  2,
+			wantWPM: []float64{50, 60, 80},
+			wantAcc: []float64{90, 92, 96},
+		},
+		{
+			name:    "empty buckets have no average until a race is in the window",
+			points:  []Point{{}, {Races: 2, AvgWPM: 70, AvgAccuracy: 95}, {}, {}},
+			window:  2,
+			wantWPM: []float64{-1, 70, 70, -1},
+			wantAcc: []float64{-1, 95, 95, -1},
+		},
+		{
+			name:    "window below one is the bucket itself",
+			points:  []Point{{Races: 1, AvgWPM: 50, AvgAccuracy: 90}, {Races: 1, AvgWPM: 70, AvgAccuracy: 94}},
+			window:  0,
+			wantWPM: []float64{50, 70},
+			wantAcc: []float64{90, 94},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movingAverages(tt.points, tt.window)
+			for i, p := range tt.points {
+				check(t, "wpm", i, p.MovingAvgWPM, tt.wantWPM[i])
+				check(t, "accuracy", i, p.MovingAvgAccuracy, tt.wantAcc[i])
+			}
+		})
+	}
+}
+
+func check(t *testing.T, name string, i int, got *float64, want float64) {
+	t.Helper()
+	switch {
+	case want < 0 && got != nil:
+		t.Errorf("point %d: moving %s = %v, want none", i, name, *got)
+	case want >= 0 && got == nil:
+		t.Errorf("point %d: moving %s missing, want %v", i, name, want)
+	case want >= 0 && *got != want:
+		t.Errorf("point %d: moving %s = %v, want %v", i, name, *got, want)
+	}
+}
@@ -0,0 +1,195 @@
+package history
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Orders results can be listed in, ties are broken by ID
+const (
+	SortCreatedAt = "created_at"
+	SortWPM       = "wpm"
+	SortAccuracy  = "accuracy"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var Sorts = []string{SortCreatedAt, SortWPM, SortAccuracy}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Filter narrows down the results of one user, zero fields match everything and To is inclusive
+type Filter struct {
+	UserID     uuid.UUID
+	Won        *bool
+	OpponentID *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	MinWPM     *int
+	Mode       models.PromptMode
+	PromptID   *uuid.UUID
+}
+
+func (f Filter) scope(tx *gorm.DB) *gorm.DB {
+	tx = tx.Where("results.user_id = ?", f.UserID)
+	if f.Won != nil {
+		tx = tx.Where("results.won = ?", *f.Won)
+	}
+	if f.OpponentID != nil {
+		tx = tx.Where("results.opponent_id = ?", *f.OpponentID)
+	}
+	if f.From != nil {
+		tx = tx.Where("results.created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		tx = tx.Where("results.created_at < ?", f.To.AddDate(0, 0, 1))
+	}
+	if f.MinWPM != nil {
+		tx = tx.Where("results.wpm >= ?", *f.MinWPM)
+	}
+	if f.PromptID != nil {
+		tx = tx.Where("results.prompt_id = ?", *f.PromptID)
+	}
+	if f.Mode != "" {
+		tx = tx.Where("results.mode = ?", f.Mode)
+	}
+	return tx
+}
+
+// Cursor marks the last result of a page for the same sort and order
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode makes the cursor opaque to clients
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reverses Encode
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// sortValue is the sort column of a result, as stored in a cursor
+func sortValue(sort string, r models.Results) string {
+	switch sort {
+	case SortWPM:
+		return strconv.Itoa(r.WPM)
+	case SortAccuracy:
+		return strconv.FormatFloat(r.Accuracy, 'f', -1, 64)
+	}
+	return r.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// parseValue turns a cursor value back into something comparable with the sort column
+func parseValue(sort, value string) (interface{}, error) {
+	switch sort {
+	case SortWPM:
+		return strconv.Atoi(value)
+	case SortAccuracy:
+		return strconv.ParseFloat(value, 64)
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// Page selects one page of a listing, After is nil for the first
+type Page struct {
+	Sort  string
+	Order string
+	Size  int
+	After *Cursor
+}
+
+// Listing is one page of results, the next cursor and the total matching the filter
+type Listing struct {
+	Results    []models.Results
+	NextCursor string
+	Total      int64
+}
+
+// ListResults pages through the user's results using keyset pagination
+func ListResults(db *gorm.DB, filter Filter, page Page) (Listing, error) {
+	var listing Listing
+
+	if page.Sort == "" {
+		page.Sort = SortCreatedAt
+	}
+	if page.Order == "" {
+		page.Order = OrderDesc
+	}
+	if page.Size <= 0 {
+		page.Size = DefaultPageSize
+	}
+	page.Size = min(page.Size, MaxPageSize)
+
+	if err := db.Model(&models.Results{}).Scopes(filter.scope).Count(&listing.Total).Error; err != nil {
+		return listing, err
+	}
+
+	column := "results." + page.Sort
+	cmp := "<"
+	if page.Order == OrderAsc {
+		cmp = ">"
+	}
+
+	query := db.Preload("User").
+		Preload("Opponent").
+		Scopes(filter.scope).
+		Order(fmt.Sprintf("%s %s, results.id %s", column, page.Order, page.Order)).
+		Limit(page.Size + 1)
+
+	if page.After != nil {
+		if page.After.Sort != page.Sort || page.After.Order != page.Order {
+			return listing, ErrInvalidCursor
+		}
+		value, err := parseValue(page.Sort, page.After.Value)
+		if err != nil {
+			return listing, ErrInvalidCursor
+		}
+		query = query.Where(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND results.id %s ?))", column, cmp, column, cmp),
+			value, value, page.After.ID,
+		)
+	}
+
+	if err := query.Find(&listing.Results).Error; err != nil {
+		return listing, err
+	}
+
+	if len(listing.Results) > page.Size {
+		listing.Results = listing.Results[:page.Size]
+		last := listing.Results[page.Size-1]
+		listing.NextCursor = Cursor{
+			Sort:  page.Sort,
+			Order: page.Order,
+			Value: sortValue(page.Sort, last),
+			ID:    last.ID,
+		}.Encode()
+	}
+	return listing, nil
+}
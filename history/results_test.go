@@ -0,0 +1,111 @@
+package history
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("7f1c2d9e-3b4a-4c5d-8e6f-0a1b2c3d4e5f")
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"created_at", Cursor{Sort: SortCreatedAt, Order: OrderDesc, Value: "2025-01-31T10:00:00.123456Z", ID: id}},
+		{"wpm", Cursor{Sort: SortWPM, Order: OrderAsc, Value: "87", ID: id}},
+		{"accuracy", Cursor{Sort: SortAccuracy, Order: OrderDesc, Value: "97.25", ID: id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if got != tt.cursor {
+				t.Errorf("DecodeCursor() = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "not a cursor!"},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("wpm:87"))},
+		{"missing ID", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"wpm","o":"asc","v":"87"}`))},
+		{"bad ID", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"wpm","o":"asc","v":"87","id":"nope"}`))},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"wpm","o":"asc","v":"87","id":"7f1c2d9e-3b4a-4c5d-8e6f-0a1b2c3d4e5f"}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor); err != ErrInvalidCursor {
+				t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+}
+
+func TestSortValueRoundTrip(t *testing.T) {
+	result := models.Results{
+		WPM:       87,
+		Accuracy:  97.25,
+		CreatedAt: time.Date(2025, 1, 31, 10, 0, 0, 123456000, time.FixedZone("CET", 3600)),
+	}
+
+	tests := []struct {
+		sort  string
+		value string
+		want  interface{}
+	}{
+		{SortWPM, "87", 87},
+		{SortAccuracy, "97.25", 97.25},
+		{SortCreatedAt, "2025-01-31T09:00:00.123456Z", result.CreatedAt.UTC()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			value := sortValue(tt.sort, result)
+			if value != tt.value {
+				t.Fatalf("sortValue() = %q, want %q", value, tt.value)
+			}
+			got, err := parseValue(tt.sort, value)
+			if err != nil {
+				t.Fatalf("parseValue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValueRejects(t *testing.T) {
+	tests := []struct {
+		sort  string
+		value string
+	}{
+		{SortWPM, "fast"},
+		{SortWPM, "87.5"},
+		{SortAccuracy, "high"},
+		{SortCreatedAt, "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort+"/"+tt.value, func(t *testing.T) {
+			if _, err := parseValue(tt.sort, tt.value); err == nil {
+				t.Errorf("parseValue(%q, %q) succeeded, want an error", tt.sort, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package keystats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/scoring"
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxGapMs drops latency samples across pauses, they say nothing about
+	// how fast a transition is typed
+	maxGapMs = 2000
+
+	// maxEvents caps how many keystrokes are kept per player and race
+	maxEvents = 5000
+)
+
+// Event is a keystroke: the key, its position in the prompt and ms since the race started
+type Event struct {
+	Key string `json:"k"`
+	Pos int    `json:"p"`
+	At  int64  `json:"t"`
+}
+
+// Count is what a tally keeps per key or bigram
+type Count struct {
+	Hits    int
+	Misses  int
+	Samples int
+	TotalMs int64
+}
+
+// Tally accumulates one player's keystrokes over a race
+type Tally struct {
+	Keys    map[string]*Count
+	Bigrams map[string]*Count
+	prompt  []string
+	events  int
+	prev    string
+	prevPos int
+	prevAt  int64
+}
+
+func NewTally(prompt string) *Tally {
+	return &Tally{Keys: map[string]*Count{}, Bigrams: map[string]*Count{}, prompt: scoring.Graphemes(prompt)}
+}
+
+func count(m map[string]*Count, seq string) *Count {
+	c, ok := m[seq]
+	if !ok {
+		c = &Count{}
+		m[seq] = c
+	}
+	return c
+}
+
+// Add folds a batch of events into the tally, keys are case-folded per physical key
+func (t *Tally) Add(events []Event) {
+	for _, e := range events {
+		if t.events >= maxEvents {
+			return
+		}
+		if e.Pos < 0 || e.Pos >= len(t.prompt) {
+			continue
+		}
+		t.events++
+
+		want := t.prompt[e.Pos]
+		expected := strings.ToLower(want)
+		key := count(t.Keys, expected)
+
+		// Only a key typed right after the previous one forms a bigram
+		chained := t.prev != "" && e.Pos == t.prevPos+1
+
+		if norm.NFC.String(e.Key) != want {
+			key.Misses++
+			if chained {
+				count(t.Bigrams, t.prev+expected).Misses++
+			}
+			// A mistake breaks the chain, the next transition starts fresh
+			t.prev = ""
+			continue
+		}
+		key.Hits++
+
+		if chained {
+			if gap := e.At - t.prevAt; gap > 0 && gap <= maxGapMs {
+				key.Samples++
+				key.TotalMs += gap
+
+				bigram := count(t.Bigrams, t.prev+expected)
+				bigram.Hits++
+				bigram.Samples++
+				bigram.TotalMs += gap
+			}
+		}
+		t.prev = expected
+		t.prevPos = e.Pos
+		t.prevAt = e.At
+	}
+}
+
+func (t *Tally) Empty() bool {
+	return len(t.Keys) == 0
+}
+
+// Record adds the tally to the user's stats for the day the race was played
+func Record(tx *gorm.DB, userID uuid.UUID, at time.Time, t *Tally) error {
+	y, m, d := at.UTC().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	var rows []models.KeyStat
+	for seq, c := range t.Keys {
+		rows = append(rows, models.KeyStat{UserID: userID, Day: day, Kind: models.KeyStatKey, Seq: seq,
+			Hits: c.Hits, Misses: c.Misses, Samples: c.Samples, TotalMs: c.TotalMs})
+	}
+	for seq, c := range t.Bigrams {
+		rows = append(rows, models.KeyStat{UserID: userID, Day: day, Kind: models.KeyStatBigram, Seq: seq,
+			Hits: c.Hits, Misses: c.Misses, Samples: c.Samples, TotalMs: c.TotalMs})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}, {Name: "kind"}, {Name: "seq"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"hits":     gorm.Expr("key_stats.hits + excluded.hits"),
+			"misses":   gorm.Expr("key_stats.misses + excluded.misses"),
+			"samples":  gorm.Expr("key_stats.samples + excluded.samples"),
+			"total_ms": gorm.Expr("key_stats.total_ms + excluded.total_ms"),
+		}),
+	}).CreateInBatches(&rows, 200).Error
+}
+
+// Stat is a key or bigram summed over a window
+type Stat struct {
+	Seq       string  `json:"seq"`
+	Hits      int     `json:"hits"`
+	Misses    int     `json:"misses"`
+	ErrorRate float64 `json:"error_rate"` // Percentage of presses that were wrong
+	AvgMs     float64 `json:"avg_ms"`     // Average time from the previous key
+	Samples   int     `json:"samples"`
+}
+
+// Summary is a user's key analytics over a window
+type Summary struct {
+	Keys           []Stat `json:"keys"`
+	MostMissed     []Stat `json:"most_missed"`
+	SlowestKeys    []Stat `json:"slowest_keys"`
+	SlowestBigrams []Stat `json:"slowest_bigrams"`
+}
+
+// Minimum presses and samples before a key or bigram is ranked
+const (
+	minPresses = 10
+	minSamples = 5
+	topN       = 10
+)
+
+// Load sums the user's stats of one kind since the given day, zero for all time
+func Load(db *gorm.DB, userID uuid.UUID, kind string, since time.Time) ([]Stat, error) {
+	query := db.Model(&models.KeyStat{}).
+		Select("seq, SUM(hits) AS hits, SUM(misses) AS misses, SUM(samples) AS samples, SUM(total_ms) AS total_ms").
+		Where("user_id = ? AND kind = ?", userID, kind).
+		Group("seq")
+	if !since.IsZero() {
+		query = query.Where("day >= ?", since)
+	}
+
+	var rows []struct {
+		Seq     string
+		Hits    int
+		Misses  int
+		Samples int
+		TotalMs int64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make([]Stat, 0, len(rows))
+	for _, r := range rows {
+		s := Stat{Seq: r.Seq, Hits: r.Hits, Misses: r.Misses, Samples: r.Samples}
+		if presses := r.Hits + r.Misses; presses > 0 {
+			s.ErrorRate = round2(float64(r.Misses) / float64(presses) * 100)
+		}
+		if r.Samples > 0 {
+			s.AvgMs = round2(float64(r.TotalMs) / float64(r.Samples))
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// Summarize builds the heatmap and the worst keys and bigrams since the given day
+func Summarize(db *gorm.DB, userID uuid.UUID, since time.Time) (Summary, error) {
+	var s Summary
+
+	keys, err := Load(db, userID, models.KeyStatKey, since)
+	if err != nil {
+		return s, err
+	}
+	bigrams, err := Load(db, userID, models.KeyStatBigram, since)
+	if err != nil {
+		return s, err
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Seq < keys[j].Seq })
+	s.Keys = keys
+
+	s.MostMissed = top(keys, func(st Stat) bool { return st.Hits+st.Misses >= minPresses && st.Misses > 0 },
+		func(a, b Stat) bool { return a.ErrorRate > b.ErrorRate })
+	s.SlowestKeys = top(keys, func(st Stat) bool { return st.Samples >= minSamples },
+		func(a, b Stat) bool { return a.AvgMs > b.AvgMs })
+	s.SlowestBigrams = top(bigrams, func(st Stat) bool { return st.Samples >= minSamples },
+		func(a, b Stat) bool { return a.AvgMs > b.AvgMs })
+
+	return s, nil
+}
+
+func top(stats []Stat, keep func(Stat) bool, less func(a, b Stat) bool) []Stat {
+	out := []Stat{}
+	for _, st := range stats {
+		if keep(st) {
+			out = append(out, st)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+	if len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
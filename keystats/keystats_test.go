@@ -0,0 +1,114 @@
+package keystats
+
+import "testing"
+
+func TestTallyAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		events  []Event
+		keys    map[string]Count
+		bigrams map[string]Count
+	}{
+		{
+			name:   "clean run",
+			prompt: "the",
+			events: []Event{{Key: "t", Pos: 0, At: 0}, {Key: "h", Pos: 1, At: 100}, {Key: "e", Pos: 2, At: 250}},
+			keys: map[string]Count{
+				"t": {Hits: 1},
+				"h": {Hits: 1, Samples: 1, TotalMs: 100},
+				"e": {Hits: 1, Samples: 1, TotalMs: 150},
+			},
+			bigrams: map[string]Count{
+				"th": {Hits: 1, Samples: 1, TotalMs: 100},
+				"he": {Hits: 1, Samples: 1, TotalMs: 150},
+			},
+		},
+		{
+			name:   "miss counts against the key and the bigram ending on it",
+			prompt: "the",
+			events: []Event{{Key: "t", Pos: 0, At: 0}, {Key: "g", Pos: 1, At: 100}, {Key: "h", Pos: 1, At: 300}},
+			keys: map[string]Count{
+				"t": {Hits: 1},
+				"h": {Hits: 1, Misses: 1},
+			},
+			bigrams: map[string]Count{
+				"th": {Misses: 1},
+			},
+		},
+		{
+			name:   "expected character comes from the prompt, not the client",
+			prompt: "ab",
+			events: []Event{{Key: "a", Pos: 1, At: 0}},
+			keys: map[string]Count{
+				"b": {Misses: 1},
+			},
+			bigrams: map[string]Count{},
+		},
+		{
+			name:    "positions outside the prompt are ignored",
+			prompt:  "ab",
+			events:  []Event{{Key: "a", Pos: -1}, {Key: "c", Pos: 2}},
+			keys:    map[string]Count{},
+			bigrams: map[string]Count{},
+		},
+		{
+			name:   "keys that skip ahead don't form a bigram",
+			prompt: "abc",
+			events: []Event{{Key: "a", Pos: 0, At: 0}, {Key: "c", Pos: 2, At: 100}},
+			keys: map[string]Count{
+				"a": {Hits: 1},
+				"c": {Hits: 1},
+			},
+			bigrams: map[string]Count{},
+		},
+		{
+			name:   "pauses are not timed",
+			prompt: "ab",
+			events: []Event{{Key: "a", Pos: 0, At: 0}, {Key: "b", Pos: 1, At: maxGapMs + 1}},
+			keys: map[string]Count{
+				"a": {Hits: 1},
+				"b": {Hits: 1},
+			},
+			bigrams: map[string]Count{},
+		},
+		{
+			name:   "keys are case-folded",
+			prompt: "Ab",
+			events: []Event{{Key: "A", Pos: 0, At: 0}, {Key: "b", Pos: 1, At: 100}},
+			keys: map[string]Count{
+				"a": {Hits: 1},
+				"b": {Hits: 1, Samples: 1, TotalMs: 100},
+			},
+			bigrams: map[string]Count{
+				"ab": {Hits: 1, Samples: 1, TotalMs: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := NewTally(tt.prompt)
+			tally.Add(tt.events)
+			compare(t, "key", tally.Keys, tt.keys)
+			compare(t, "bigram", tally.Bigrams, tt.bigrams)
+		})
+	}
+}
+
+func compare(t *testing.T, kind string, got map[string]*Count, want map[string]Count) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d %ss, want %d", len(got), kind, len(want))
+	}
+	for seq, w := range want {
+		g, ok := got[seq]
+		if !ok {
+			t.Errorf("%s %q missing", kind, seq)
+			continue
+		}
+		if *g != w {
+			t.Errorf("%s %q = %+v, want %+v", kind, seq, *g, w)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package leaderboard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodAllTime = "all_time"
+
+	ViewBestWPM = "best_wpm"
+	ViewAvgWPM  = "avg_wpm"
+	ViewWins    = "wins"
+
+	// Latest races the average WPM view covers by default and at most
+	RecentRaces    = 10
+	MaxRecentRaces = 100
+)
+
+var Periods = []string{PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodAllTime}
+
+var Views = []string{ViewBestWPM, ViewAvgWPM, ViewWins}
+
+type Entry struct {
+	Rank     int       `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Value    float64   `json:"value"`
+	Races    int       `json:"races"`
+	Wins     int       `json:"wins"`
+	BestWPM  int       `json:"best_wpm"`
+}
+
+// PeriodStart returns the UTC start of the bucket containing t, weeks start on Monday
+func PeriodStart(period string, t time.Time) (time.Time, error) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case PeriodDaily:
+		return day, nil
+	case PeriodWeekly:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset), nil
+	case PeriodMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case PeriodAllTime:
+		return time.Unix(0, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown period %q", period)
+}
+
+// RecordResult folds a result into every period bucket, in the tx that created it
+func RecordResult(tx *gorm.DB, result models.Results) error {
+	wins := 0
+	if result.Won {
+		wins = 1
+	}
+
+	// Every running total moves back one race and gains this one
+	recentTotals := gorm.Expr(`(ARRAY[?::bigint] || ARRAY(
+		SELECT r.total + ? FROM unnest(leaderboard_stats.recent_totals) WITH ORDINALITY AS r(total, i) ORDER BY r.i
+	))[1:?]`, result.WPM, result.WPM, MaxRecentRaces)
+
+	for _, period := range Periods {
+		start, _ := PeriodStart(period, result.CreatedAt)
+
+		stat := models.LeaderboardStat{
+			UserID:       result.UserID,
+			Period:       period,
+			PeriodStart:  start,
+			Races:        1,
+			Wins:         wins,
+			BestWPM:      result.WPM,
+			TotalWPM:     int64(result.WPM),
+			RecentTotals: models.WPMTotals{int64(result.WPM)},
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "period"}, {Name: "period_start"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"races":         gorm.Expr("leaderboard_stats.races + 1"),
+				"wins":          gorm.Expr("leaderboard_stats.wins + ?", wins),
+				"best_wpm":      gorm.Expr("GREATEST(leaderboard_stats.best_wpm, ?)", result.WPM),
+				"total_wpm":     gorm.Expr("leaderboard_stats.total_wpm + ?", result.WPM),
+				"recent_totals": recentTotals,
+				"updated_at":    time.Now(),
+			}),
+		}).Create(&stat).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rebuild recomputes every bucket from the results table
+func Rebuild(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM leaderboard_stats").Error; err != nil {
+			return err
+		}
+
+		var results []models.Results
+		return tx.Order("created_at ASC").FindInBatches(&results, 500, func(batch *gorm.DB, _ int) error {
+			for _, r := range results {
+				if err := RecordResult(tx, r); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	})
+}
+
+// RebuildIfEmpty backfills the aggregates on first start or when rows lack recent totals
+func RebuildIfEmpty(db *gorm.DB) error {
+	var count, stale int64
+	if err := db.Model(&models.LeaderboardStat{}).Count(&count).Error; err != nil {
+		return err
+	}
+	err := db.Model(&models.LeaderboardStat{}).
+		Where("races > 0 AND cardinality(recent_totals) = 0").
+		Count(&stale).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 && stale == 0 {
+		return nil
+	}
+	return Rebuild(db)
+}
+
+// Query returns the top entries for a view in the current bucket of the period
+func Query(db *gorm.DB, view, period string, minRaces, recent, limit int) ([]Entry, error) {
+	start, err := PeriodStart(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var value string
+	var args []interface{}
+	query := db.Table("leaderboard_stats").
+		Joins("JOIN users ON users.id = leaderboard_stats.user_id").
+		Where("leaderboard_stats.period = ? AND leaderboard_stats.period_start = ?", period, start)
+
+	switch view {
+	case ViewBestWPM:
+		value = "leaderboard_stats.best_wpm"
+	case ViewWins:
+		value = "leaderboard_stats.wins"
+		query = query.Where("leaderboard_stats.wins > 0")
+	case ViewAvgWPM:
+		n := "LEAST(?::int, cardinality(leaderboard_stats.recent_totals))"
+		value = "leaderboard_stats.recent_totals[" + n + "]::float / " + n
+		args = append(args, recent, recent)
+		query = query.Where("leaderboard_stats.races >= ?", minRaces)
+	default:
+		return nil, fmt.Errorf("unknown view %q", view)
+	}
+
+	var entries []Entry
+	err = query.
+		Select("leaderboard_stats.user_id, users.username, "+value+" AS value, "+
+			"leaderboard_stats.races, leaderboard_stats.wins, leaderboard_stats.best_wpm", args...).
+		Order("value DESC, leaderboard_stats.races DESC").
+		Limit(limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries, nil
+}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"os"
 
 	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/history"
+	"github.com/Nitesh-04/realtime-racing/leaderboard"
+	"github.com/Nitesh-04/realtime-racing/matchmaking"
 	"github.com/Nitesh-04/realtime-racing/middleware"
+	"github.com/Nitesh-04/realtime-racing/percentile"
+	"github.com/Nitesh-04/realtime-racing/prompts"
 	"github.com/Nitesh-04/realtime-racing/routes"
+	"github.com/Nitesh-04/realtime-racing/seasons"
 	"github.com/Nitesh-04/realtime-racing/websockets"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -15,14 +23,37 @@ import (
 
 func init() {
 	config.ConnectDB()
+
+	if err := leaderboard.RebuildIfEmpty(config.DB); err != nil {
+		log.Printf("Failed to backfill leaderboards: %v", err)
+	}
+
+	if err := prompts.Backfill(config.DB); err != nil {
+		log.Printf("Failed to backfill prompts: %v", err)
+	}
+
+	if err := history.Backfill(config.DB); err != nil {
+		log.Printf("Failed to backfill result modes: %v", err)
+	}
+
+	if err := prompts.Seed(config.DB); err != nil {
+		log.Printf("Failed to seed prompt library: %v", err)
+	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "prompts" {
+		os.Exit(runPromptsCommand(os.Args[2:]))
+	}
+
 	app := fiber.New()
 
 	setupMiddlewares(app)
 	setupRoutes(app)
 	setupWebSocketRoutes(app)
+	matchmaking.Start()
+	seasons.StartArchiver(config.DB)
+	percentile.Start(config.DB)
 	startServer(app)
 }
 
@@ -52,6 +83,12 @@ func setupWebSocketRoutes(app *fiber.App) {
 		return fiber.ErrUpgradeRequired
 	})
 
+	// Registered before /ws/:room_code so "lobby" is not taken as a room code
+	// The lobby pushes join tickets, so it only serves the token's owner
+	app.Get("/ws/lobby", middleware.CheckSocketAuth(), websocket.New(func(c *websocket.Conn) {
+		websockets.Lobby.HandleConnection(c)
+	}))
+
 	app.Get("/ws/:room_code", websocket.New(func(c *websocket.Conn) {
 		websockets.Hub.HandleConnection(c)
 	}))
@@ -0,0 +1,260 @@
+package matchmaking
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/constants"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+	"github.com/Nitesh-04/realtime-racing/websockets"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	tickInterval = 2 * time.Second
+
+	// A player's acceptable WPM gap starts at baseBand and widens by
+	// bandStep every bandInterval spent waiting, up to maxBand
+	baseBand     = 10.0
+	bandStep     = 5.0
+	bandInterval = 5 * time.Second
+	maxBand      = 100.0
+
+	// recentRaces is how many results feed a player's matchmaking WPM
+	recentRaces = 10
+
+	// defaultWPM is used for players without any results yet
+	defaultWPM = 40.0
+
+	// matchTTL is how long a found match is kept for status polling
+	matchTTL = 2 * time.Minute
+)
+
+type entry struct {
+	UserID   uuid.UUID
+	Username string
+	WPM      float64
+	JoinedAt time.Time
+}
+
+// Match is handed to each side once paired
+type Match struct {
+	RoomCode  string    `json:"room_code"`
+	Ticket    string    `json:"ticket"`
+	Opponent  string    `json:"opponent"`
+	Prompt    string    `json:"prompt"`
+	MatchedAt time.Time `json:"matched_at"`
+}
+
+type MatchQueue struct {
+	entries map[uuid.UUID]*entry
+	matches map[uuid.UUID]Match
+	mu      sync.Mutex
+}
+
+var Queue = &MatchQueue{
+	entries: make(map[uuid.UUID]*entry),
+	matches: make(map[uuid.UUID]Match),
+}
+
+// band returns the WPM gap a player accepts after waiting for the given time
+func band(waited time.Duration) float64 {
+	steps := math.Floor(float64(waited) / float64(bandInterval))
+	return math.Min(baseBand+steps*bandStep, maxBand)
+}
+
+// RecentAverageWPM averages the user's last few results
+func RecentAverageWPM(db *gorm.DB, userID uuid.UUID) (float64, error) {
+	var avg *float64
+	err := db.Raw(`SELECT AVG(wpm) FROM (
+			SELECT wpm FROM results WHERE user_id = ? ORDER BY created_at DESC LIMIT ?
+		) recent`, userID, recentRaces).Scan(&avg).Error
+	if err != nil {
+		return 0, err
+	}
+	if avg == nil {
+		return defaultWPM, nil
+	}
+	return *avg, nil
+}
+
+// Join puts the user in the queue
+func (q *MatchQueue) Join(user models.User) (float64, error) {
+	wpm, err := RecentAverageWPM(config.DB, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, queued := q.entries[user.ID]; queued {
+		return 0, fmt.Errorf("already in queue")
+	}
+
+	delete(q.matches, user.ID)
+	q.entries[user.ID] = &entry{
+		UserID:   user.ID,
+		Username: user.Username,
+		WPM:      wpm,
+		JoinedAt: time.Now(),
+	}
+
+	log.Printf("Matchmaking: '%s' joined queue (wpm=%.1f)", user.Username, wpm)
+	return wpm, nil
+}
+
+// Leave removes the user from the queue and reports whether they were queued
+func (q *MatchQueue) Leave(userID uuid.UUID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, queued := q.entries[userID]
+	delete(q.entries, userID)
+	return queued
+}
+
+// Status returns whether the user is queued and their match if one was found
+func (q *MatchQueue) Status(userID uuid.UUID) (bool, *Match) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, queued := q.entries[userID]
+	if m, ok := q.matches[userID]; ok {
+		return queued, &m
+	}
+	return queued, nil
+}
+
+// pair pops compatible players off the queue, closest WPM first
+func (q *MatchQueue) pair() [][2]*entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for id, m := range q.matches {
+		if now.Sub(m.MatchedAt) > matchTTL {
+			delete(q.matches, id)
+		}
+	}
+
+	waiting := make([]*entry, 0, len(q.entries))
+	for _, e := range q.entries {
+		waiting = append(waiting, e)
+	}
+	sort.Slice(waiting, func(i, j int) bool { return waiting[i].WPM < waiting[j].WPM })
+
+	var pairs [][2]*entry
+	for i := 0; i+1 < len(waiting); {
+		a, b := waiting[i], waiting[i+1]
+		// Both players have to accept the gap, so the shorter wait decides
+		allowed := math.Min(band(now.Sub(a.JoinedAt)), band(now.Sub(b.JoinedAt)))
+		if b.WPM-a.WPM <= allowed {
+			pairs = append(pairs, [2]*entry{a, b})
+			delete(q.entries, a.UserID)
+			delete(q.entries, b.UserID)
+			i += 2
+			continue
+		}
+		i++
+	}
+	return pairs
+}
+
+// createMatch sets up a room for the pair and notifies both players
+func (q *MatchQueue) createMatch(a, b *entry) error {
+	db := config.DB
+
+	var roomCode string
+	for {
+		roomCode = constants.GenerateRoomCode()
+
+		var existingRoom models.Room
+		result := db.Where("room_code = ?", roomCode).First(&existingRoom)
+		if result.RowsAffected == 0 {
+			break
+		}
+	}
+
+	// Ranked races always use plain lowercase English text so ratings stay
+	// comparable
+	plain := false
+	prompt, err := prompts.Random(db, prompts.Filter{Language: "en", Mode: models.PromptModeText, Punctuated: &plain})
+	if err != nil {
+		return err
+	}
+
+	room := models.Room{
+		RoomCode:   roomCode,
+		CreatorID:  a.UserID,
+		OpponentID: &b.UserID,
+		RoomStatus: models.RoomStatusReady,
+		PromptID:   &prompt.ID,
+		Prompt:     prompt.Text,
+		Ranked:     true,
+	}
+
+	if err := db.Create(&room).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, side := range [][2]*entry{{a, b}, {b, a}} {
+		player, opponent := side[0], side[1]
+
+		ticket, err := websockets.Hub.IssueTicket(roomCode, player.Username)
+		if err != nil {
+			return err
+		}
+
+		match := Match{
+			RoomCode:  roomCode,
+			Ticket:    ticket,
+			Opponent:  opponent.Username,
+			Prompt:    room.Prompt,
+			MatchedAt: now,
+		}
+
+		q.mu.Lock()
+		q.matches[player.UserID] = match
+		q.mu.Unlock()
+
+		websockets.Lobby.NotifyUser(player.UserID, "match_found", match)
+	}
+
+	log.Printf("Matchmaking: paired '%s' (%.1f) with '%s' (%.1f) in room %s",
+		a.Username, a.WPM, b.Username, b.WPM, roomCode)
+	return nil
+}
+
+// requeue puts players back at the front of the queue after a failed match
+func (q *MatchQueue) requeue(entries ...*entry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, e := range entries {
+		q.entries[e.UserID] = e
+	}
+}
+
+// Start runs the matcher in the background
+func Start() {
+	ticker := time.NewTicker(tickInterval)
+	log.Printf("Starting matchmaking every %s", tickInterval)
+	go func() {
+		for range ticker.C {
+			for _, p := range Queue.pair() {
+				if err := Queue.createMatch(p[0], p[1]); err != nil {
+					log.Printf("Matchmaking: failed to create match: %v", err)
+					Queue.requeue(p[0], p[1])
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAdmin only lets through admins, it must run after CheckAuth
+func RequireAdmin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		userId, _ := c.Locals("userId").(string)
+
+		if userId == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Unauthorized",
+			})
+		}
+
+		var user models.User
+
+		if err := config.DB.Where("id = ?", userId).First(&user).Error; err != nil || !user.IsAdmin {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin access required",
+			})
+		}
+
+		return c.Next()
+	}
+}
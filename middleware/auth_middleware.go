@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"os"
 	"strings"
 
@@ -53,34 +54,77 @@ func CheckAuth() fiber.Handler {
 			})
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid token signing method")
-			}
-
-			return []byte(os.Getenv("JWT_SECRET_KEY")), nil // Use the secret key from environment variables
-		})
+		userID, err := UserIDFromToken(tokenString)
 
+		if errors.Is(err, errInvalidClaims) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid token claims",
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid token",
 			})
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			userID, ok := claims["userId"].(string)
-			if !ok {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Invalid token claims",
-				})
-			}
+		c.Locals("userId", userID) // Store user ID in context for later use
+		return c.Next()
+	}
+}
 
-			c.Locals("userId", userID) // Store user ID in context for later use
-			return c.Next()
+var (
+	errInvalidToken  = errors.New("invalid token")
+	errInvalidClaims = errors.New("invalid token claims")
+)
+
+// UserIDFromToken validates a JWT and returns the user ID it was issued for
+func UserIDFromToken(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid token signing method")
+		}
+
+		return []byte(os.Getenv("JWT_SECRET_KEY")), nil // Use the secret key from environment variables
+	})
+
+	if err != nil {
+		return "", errInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", errInvalidToken
+	}
+
+	userID, ok := claims["userId"].(string)
+	if !ok || userID == "" {
+		return "", errInvalidClaims
+	}
+	return userID, nil
+}
+
+// CheckSocketAuth authenticates a websocket upgrade, the token may come as ?token=
+func CheckSocketAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString := c.Query("token")
+		if authHeader := c.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			tokenString = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+
+		if tokenString == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token is missing",
+			})
+		}
+
+		userID, err := UserIDFromToken(tokenString)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid token",
+			})
 		}
 
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
+		c.Locals("userId", userID)
+		return c.Next()
 	}
 }
\ No newline at end of file
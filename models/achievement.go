@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// UserAchievement records when a user unlocked an achievement of the catalog
+type UserAchievement struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Key string `gorm:"not null;uniqueIndex:idx_user_achievement" json:"key"`
+
+	// Result that unlocked it
+	ResultID *uuid.UUID `gorm:"type:uuid" json:"result_id"`
+	Result   *Results   `gorm:"foreignKey:ResultID;constraint:OnDelete:SET NULL" json:"-"`
+
+	UnlockedAt time.Time `gorm:"not null" json:"unlocked_at"`
+}
+
+func (a *UserAchievement) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
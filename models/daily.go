@@ -0,0 +1,54 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// DailyChallenge is the prompt picked for one UTC day, with its text copied
+type DailyChallenge struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	// Midnight UTC of the day
+	Day time.Time `gorm:"type:date;not null;uniqueIndex" json:"day"`
+
+	PromptID *uuid.UUID `gorm:"type:uuid" json:"prompt_id"`
+	Prompt   string     `gorm:"not null" json:"prompt"`
+	Language string     `gorm:"not null;default:'en'" json:"language"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// DailyAttempt is one solo run at a daily challenge, scored by the server
+type DailyAttempt struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	ChallengeID uuid.UUID      `gorm:"type:uuid;not null;index:idx_daily_attempt_user" json:"challenge_id"`
+	Challenge   DailyChallenge `gorm:"foreignKey:ChallengeID;constraint:OnDelete:CASCADE" json:"-"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_daily_attempt_user" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	WPM      int     `gorm:"not null;default:0" json:"wpm"`
+	RawWPM   int     `gorm:"not null;default:0" json:"raw_wpm"`
+	Accuracy float64 `gorm:"not null;default:0" json:"accuracy"`
+	Errors   int     `gorm:"not null;default:0" json:"errors"`
+	Progress float64 `gorm:"not null;default:0" json:"progress"`
+
+	// Only attempts that typed the whole prompt make the leaderboard
+	Completed bool `gorm:"not null;default:false" json:"completed"`
+}
+
+func (d *DailyChallenge) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.New()
+	return
+}
+
+func (a *DailyAttempt) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.New()
+	return
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// KeyStat aggregates a user's keystrokes on one key or bigram over one UTC day
+type KeyStat struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_key_stat_bucket" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Day  time.Time `gorm:"type:date;not null;uniqueIndex:idx_key_stat_bucket" json:"day"`
+	Kind string    `gorm:"not null;uniqueIndex:idx_key_stat_bucket" json:"kind"` // "key" or "bigram"
+	Seq  string    `gorm:"not null;uniqueIndex:idx_key_stat_bucket" json:"seq"`
+
+	Hits   int `gorm:"not null;default:0" json:"hits"`
+	Misses int `gorm:"not null;default:0" json:"misses"`
+
+	// Latency samples, the time from the previous correct key
+	Samples int   `gorm:"not null;default:0" json:"samples"`
+	TotalMs int64 `gorm:"not null;default:0" json:"total_ms"`
+}
+
+const (
+	KeyStatKey    = "key"
+	KeyStatBigram = "bigram"
+)
+
+func (k *KeyStat) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ID = uuid.New()
+	return
+}
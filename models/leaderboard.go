@@ -0,0 +1,81 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LeaderboardStat is a user's aggregate of results within one period bucket
+type LeaderboardStat struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_leaderboard_bucket" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Period      string    `gorm:"not null;uniqueIndex:idx_leaderboard_bucket;index:idx_leaderboard_period" json:"period"`
+	PeriodStart time.Time `gorm:"not null;uniqueIndex:idx_leaderboard_bucket;index:idx_leaderboard_period" json:"period_start"`
+
+	Races    int   `gorm:"not null;default:0" json:"races"`
+	Wins     int   `gorm:"not null;default:0" json:"wins"`
+	BestWPM  int   `gorm:"not null;default:0" json:"best_wpm"`
+	TotalWPM int64 `gorm:"not null;default:0" json:"total_wpm"`
+
+	// RecentTotals[i] is the summed WPM of the latest i+1 races in the bucket
+	RecentTotals WPMTotals `gorm:"type:bigint[];not null;default:'{}'" json:"-"`
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (l *LeaderboardStat) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.New()
+	return
+}
+
+// WPMTotals is stored as a postgres bigint[]
+type WPMTotals []int64
+
+func (t WPMTotals) Value() (driver.Value, error) {
+	parts := make([]string, len(t))
+	for i, v := range t {
+		parts[i] = strconv.FormatInt(v, 10)
+	}
+	return "{" + strings.Join(parts, ",") + "}", nil
+}
+
+func (t *WPMTotals) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into WPMTotals", src)
+	}
+
+	s = strings.Trim(s, "{}")
+	if s == "" {
+		*t = WPMTotals{}
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	totals := make(WPMTotals, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return err
+		}
+		totals[i] = n
+	}
+	*t = totals
+	return nil
+}
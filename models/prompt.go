@@ -0,0 +1,94 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Prompt is a passage in the library, retired ones are kept for old rooms and results
+type Prompt struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	Text string `gorm:"not null" json:"text"`
+
+	// Hash of the normalized text, used to deduplicate the library
+	Hash *string `gorm:"uniqueIndex" json:"-"`
+
+	// Where the passage comes from, for attribution
+	Source string `json:"source"`
+
+	// Code prompts keep their newlines and indentation
+	Mode PromptMode `gorm:"not null;default:'text';index" json:"mode"`
+
+	// Programming language of a code prompt, for syntax highlighting
+	CodeLanguage string `gorm:"index" json:"code_language,omitempty"`
+
+	Language   string           `gorm:"not null;default:'en';index" json:"language"`
+	Tags       []string         `gorm:"serializer:json;type:jsonb" json:"tags"`
+	Difficulty PromptDifficulty `gorm:"not null;default:'medium';index" json:"difficulty"`
+
+	// Computed by the prompt analyzer, 0 is trivial and 100 very hard
+	DifficultyScore float64 `gorm:"not null;default:0" json:"difficulty_score"`
+
+	// Whether the text has capitals, punctuation or digits
+	Punctuated bool `gorm:"not null;default:false;index" json:"punctuated"`
+
+	// Not defaulted in the DB, gorm would skip an explicit false on create
+	Active bool `gorm:"not null;index" json:"active"`
+
+	// User submissions wait for an admin before they go into rotation
+	Status          PromptStatus `gorm:"not null;default:'approved';index" json:"status"`
+	SubmittedByID   *uuid.UUID   `gorm:"type:uuid;index" json:"submitted_by_id,omitempty"`
+	SubmittedBy     *User        `gorm:"foreignKey:SubmittedByID;constraint:OnDelete:SET NULL" json:"submitted_by,omitempty"`
+	ReviewedAt      *time.Time   `json:"reviewed_at,omitempty"`
+	RejectionReason string       `json:"rejection_reason,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type PromptDifficulty string
+const (
+	PromptDifficultyEasy   PromptDifficulty = "easy"
+	PromptDifficultyMedium PromptDifficulty = "medium"
+	PromptDifficultyHard   PromptDifficulty = "hard"
+)
+
+func (d PromptDifficulty) Valid() bool {
+	switch d {
+	case PromptDifficultyEasy, PromptDifficultyMedium, PromptDifficultyHard:
+		return true
+	}
+	return false
+}
+
+type PromptStatus string
+const (
+	PromptStatusPending  PromptStatus = "pending"
+	PromptStatusApproved PromptStatus = "approved"
+	PromptStatusRejected PromptStatus = "rejected"
+)
+
+func (s PromptStatus) Valid() bool {
+	switch s {
+	case PromptStatusPending, PromptStatusApproved, PromptStatusRejected:
+		return true
+	}
+	return false
+}
+
+type PromptMode string
+const (
+	PromptModeText PromptMode = "text"
+	PromptModeCode PromptMode = "code"
+)
+
+func (m PromptMode) Valid() bool {
+	return m == PromptModeText || m == PromptModeCode
+}
+
+func (p *Prompt) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
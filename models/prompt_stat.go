@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// PromptStat aggregates every result raced on a library prompt
+type PromptStat struct {
+	PromptID uuid.UUID `gorm:"type:uuid;primaryKey" json:"prompt_id"`
+	Prompt   Prompt    `gorm:"foreignKey:PromptID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Races    int   `gorm:"not null;default:0" json:"races"`
+	BestWPM  int   `gorm:"not null;default:0" json:"best_wpm"`
+	TotalWPM int64 `gorm:"not null;default:0" json:"-"`
+
+	// Player holding the best WPM, the first to reach it keeps the record
+	RecordHolderID *uuid.UUID `gorm:"type:uuid" json:"record_holder_id"`
+	RecordHolder   *User      `gorm:"foreignKey:RecordHolderID;constraint:OnDelete:SET NULL" json:"record_holder,omitempty"`
+	RecordSetAt    *time.Time `json:"record_set_at"`
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s PromptStat) AvgWPM() float64 {
+	if s.Races == 0 {
+		return 0
+	}
+	return float64(s.TotalWPM) / float64(s.Races)
+}
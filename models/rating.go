@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// UserRating is the current Glicko-2 rating of a user in ranked races
+type UserRating struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Rating     float64 `gorm:"not null" json:"rating"`
+	RD         float64 `gorm:"not null" json:"rd"`
+	Volatility float64 `gorm:"not null" json:"volatility"`
+	RatedRaces int     `gorm:"not null;default:0" json:"rated_races"`
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// RatingHistory records every rating change caused by a ranked race
+type RatingHistory struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	ResultID uuid.UUID `gorm:"type:uuid;not null" json:"result_id"`
+	Result   Results   `gorm:"foreignKey:ResultID;constraint:OnDelete:CASCADE" json:"-"`
+
+	// Rooms are deleted after the race, so only the code is kept
+	RoomCode string `gorm:"not null" json:"room_code"`
+
+	RatingBefore float64 `gorm:"not null" json:"rating_before"`
+	RatingAfter  float64 `gorm:"not null" json:"rating_after"`
+	RDBefore     float64 `gorm:"not null" json:"rd_before"`
+	RDAfter      float64 `gorm:"not null" json:"rd_after"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (r *RatingHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return
+}
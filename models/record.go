@@ -0,0 +1,62 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Personal bests are kept per race length and mode, and per library prompt
+const (
+	RecordScopeDuration = "duration"
+	RecordScopePrompt   = "prompt"
+)
+
+// PersonalBest is a user's fastest result in one scope, Key is "text:15" or a prompt ID
+type PersonalBest struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_personal_best" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Scope string `gorm:"not null;uniqueIndex:idx_personal_best" json:"scope"`
+	Key   string `gorm:"not null;uniqueIndex:idx_personal_best" json:"key"`
+
+	Mode     PromptMode `gorm:"not null;default:'text'" json:"mode"`
+	Duration int        `gorm:"not null;default:0" json:"duration,omitempty"`
+
+	PromptID *uuid.UUID `gorm:"type:uuid" json:"prompt_id,omitempty"`
+	Prompt   *Prompt    `gorm:"foreignKey:PromptID;constraint:OnDelete:CASCADE" json:"prompt,omitempty"`
+
+	ResultID *uuid.UUID `gorm:"type:uuid" json:"result_id"`
+	Result   *Results   `gorm:"foreignKey:ResultID;constraint:OnDelete:SET NULL" json:"-"`
+
+	WPM      int     `gorm:"not null" json:"wpm"`
+	RawWPM   int     `gorm:"not null;default:0" json:"raw_wpm"`
+	Accuracy float64 `gorm:"not null" json:"accuracy"`
+
+	// WPM of the best this one replaced, nil for a first record
+	PreviousWPM *int `json:"previous_wpm"`
+
+	SetAt time.Time `gorm:"not null" json:"set_at"`
+}
+
+func (p *PersonalBest) BeforeCreate(tx *gorm.DB) (err error) {
+	p.ID = uuid.New()
+	return
+}
+
+// UserStreak tracks consecutive wins and consecutive UTC days with a race
+type UserStreak struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	WinStreak     int `gorm:"not null;default:0" json:"win_streak"`
+	BestWinStreak int `gorm:"not null;default:0" json:"best_win_streak"`
+
+	DailyStreak     int        `gorm:"not null;default:0" json:"daily_streak"`
+	BestDailyStreak int        `gorm:"not null;default:0" json:"best_daily_streak"`
+	LastActiveDay   *time.Time `gorm:"type:date" json:"last_active_day"`
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -15,11 +15,35 @@ type Results struct {
 	OpponentID uuid.UUID `gorm:"type:uuid;not null" json:"opponent_id"`
 	Opponent User `gorm:"foreignKey:OpponentID;constraint:OnDelete:CASCADE"`
 
+	// Room the race was played in and the library prompt it used, nil for
+	// older results and custom or generated texts
+	RoomID *uuid.UUID `gorm:"type:uuid;index" json:"room_id"`
+	Room *Room `gorm:"foreignKey:RoomID;constraint:OnDelete:SET NULL" json:"room,omitempty"`
+
+	PromptID *uuid.UUID `gorm:"type:uuid;index" json:"prompt_id"`
+	Prompt *Prompt `gorm:"foreignKey:PromptID;constraint:OnDelete:SET NULL" json:"-"`
+
+	// Season the race was played in, nil outside of any season
+	SeasonID *uuid.UUID `gorm:"type:uuid;index" json:"season_id"`
+	Season *Season `gorm:"foreignKey:SeasonID;constraint:OnDelete:SET NULL" json:"-"`
+
 	Won  bool `json:"won"`
 
+	// Mode and length in seconds of the race, the room is gone soon after it
+	Mode PromptMode `gorm:"not null;default:'text';index" json:"mode"`
+	Duration int `gorm:"not null;default:0" json:"duration"`
+
+	// WPM is net of the Error left in the text, Consistency is 0-100
 	WPM int `gorm:"not null" json:"wpm"`
+	RawWPM int `gorm:"not null;default:0" json:"raw_wpm"`
 	Accuracy float64 `gorm:"not null" json:"accuracy"`
 	Error float64 `gorm:"not null" json:"error"`
+	CorrectedErrors int `gorm:"not null;default:0" json:"corrected_errors"`
+	Consistency float64 `gorm:"not null;default:0" json:"consistency"`
+
+	// Characters typed and the share of the prompt they cover (0-100)
+	Typed int `gorm:"not null;default:0" json:"typed"`
+	Progress float64 `gorm:"not null;default:0" json:"progress"`
 
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
@@ -27,5 +51,19 @@ type Results struct {
 
 func (r *Results) BeforeCreate(tx *gorm.DB) (err error) {
     r.ID = uuid.New()
+
+    // Tag the result with the season running right now, if any
+    if r.SeasonID == nil {
+        var season Season
+        now := time.Now()
+        err = tx.Session(&gorm.Session{NewDB: true}).
+            Where("starts_at <= ? AND ends_at > ?", now, now).
+            Order("starts_at DESC").
+            Limit(1).
+            Find(&season).Error
+        if err == nil && season.ID != uuid.Nil {
+            r.SeasonID = &season.ID
+        }
+    }
     return
 }
\ No newline at end of file
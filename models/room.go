@@ -17,10 +17,40 @@ type Room struct {
 	OpponentID *uuid.UUID `gorm:"type:uuid;" json:"opponent_id"`
 	Opponent User `gorm:"foreignKey:OpponentID;constraint:OnDelete:CASCADE"`
 
+	// Library prompt the race uses, Prompt keeps a copy of its text
+	PromptID *uuid.UUID `gorm:"type:uuid" json:"prompt_id"`
 	Prompt string `gorm:"not null" json:"prompt"`
 
+	// Custom rooms race on a private text from the creator, it never enters
+	// the library
+	Custom bool `gorm:"not null;default:false" json:"custom"`
+
+	// Language of the prompt, decides how WPM counts words
+	Language string `gorm:"not null;default:'en'" json:"language"`
+
+	// Code rooms race on a snippet in CodeLanguage
+	Mode         PromptMode `gorm:"not null;default:'text'" json:"mode"`
+	CodeLanguage string     `json:"code_language,omitempty"`
+
+	// Generated rooms race on random words from WordList, WordSeed
+	// reproduces the text
+	WordList string `json:"word_list,omitempty"`
+	WordSeed int64  `json:"word_seed,omitempty"`
+
+	// How long the race lasts, in seconds
+	Duration int `gorm:"not null;default:15" json:"duration"`
+
+	// Race on text with capitals, punctuation and numbers
+	Punctuation bool `gorm:"not null;default:false" json:"punctuation"`
+
 	RoomStatus RoomStatus `gorm:"not null;default:'waiting'" json:"status"`
 
+	// Practice rooms are solo drills built from the creator's weak keys
+	Practice bool `gorm:"not null;default:false" json:"practice"`
+
+	// Ranked rooms update the players' ratings when the race ends
+	Ranked bool `gorm:"not null;default:false" json:"ranked"`
+
 	WinnerID *uuid.UUID `gorm:"type:uuid" json:"winner_id"`
 	Winner User `gorm:"foreignKey:WinnerID;constraint:OnDelete:SET NULL"`
 
@@ -36,6 +66,11 @@ const (
 	RoomStatusCompleted  RoomStatus = "completed"
 )
 
+// Race lengths a room can pick, in seconds
+const DefaultRaceDuration = 15
+
+var RaceDurations = []int{15, 30, 60, 120}
+
 func (r *Room) BeforeCreate(tx *gorm.DB) (err error) {
 	r.ID = uuid.New()
 	return
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Season is a competitive window, results created within it are tagged with it
+type Season struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	Name string `gorm:"not null" json:"name"`
+
+	StartsAt time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt   time.Time `gorm:"not null;index" json:"ends_at"`
+
+	// Archived seasons serve their standings from SeasonStanding
+	Archived   bool       `gorm:"not null;default:false" json:"archived"`
+	ArchivedAt *time.Time `json:"archived_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SeasonStanding is the final standing of a user, snapshotted when the season is archived
+type SeasonStanding struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;" json:"id"`
+
+	SeasonID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_season_user" json:"season_id"`
+	Season   Season    `gorm:"foreignKey:SeasonID;constraint:OnDelete:CASCADE" json:"-"`
+
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_season_user" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	// Kept so archived standings survive username changes
+	Username string `gorm:"not null" json:"username"`
+
+	Rank    int     `gorm:"not null" json:"rank"`
+	Races   int     `gorm:"not null" json:"races"`
+	Wins    int     `gorm:"not null" json:"wins"`
+	AvgWPM  float64 `gorm:"not null" json:"avg_wpm"`
+	BestWPM int     `gorm:"not null" json:"best_wpm"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (s *Season) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return
+}
+
+func (s *SeasonStanding) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return
+}
@@ -14,6 +14,8 @@ type User struct {
 
 	Email string `gorm:"uniqueIndex;not null" json:"email"`
 	Password string `gorm:"not null" json:"-"`
+
+	IsAdmin bool `gorm:"not null;default:false" json:"is_admin"`
 	
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
@@ -0,0 +1,117 @@
+package percentile
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// ActiveWindow is how far back a player must have raced to count, their
+	// WPM is the average over the races in it
+	ActiveWindow = 30 * 24 * time.Hour
+
+	// RebuildInterval is how often the histogram is recomputed
+	RebuildInterval = 10 * time.Minute
+
+	// MaxWPM is the last histogram bucket, faster players share it
+	MaxWPM = 300
+)
+
+// Histogram counts active players per whole WPM
+type Histogram struct {
+	mu      sync.RWMutex
+	buckets [MaxWPM + 1]int
+	players int
+	builtAt time.Time
+}
+
+// Current is the histogram kept up to date by Start
+var Current = &Histogram{}
+
+// Standing is where a player's WPM falls among active players
+type Standing struct {
+	WPM        float64   `json:"wpm"`
+	Percentile float64   `json:"percentile"`
+	Players    int       `json:"players"`
+	AsOf       time.Time `json:"as_of"`
+}
+
+func bucket(wpm float64) int {
+	return min(max(int(math.Floor(wpm)), 0), MaxWPM)
+}
+
+// Rebuild recomputes the histogram from every active player's average WPM
+func (h *Histogram) Rebuild(db *gorm.DB, now time.Time) error {
+	var averages []float64
+	err := db.Table("results").
+		Where("created_at >= ?", now.Add(-ActiveWindow)).
+		Group("user_id").
+		Pluck("AVG(wpm)", &averages).Error
+	if err != nil {
+		return err
+	}
+
+	var buckets [MaxWPM + 1]int
+	for _, wpm := range averages {
+		buckets[bucket(wpm)]++
+	}
+
+	h.mu.Lock()
+	h.buckets = buckets
+	h.players = len(averages)
+	h.builtAt = now
+	h.mu.Unlock()
+	return nil
+}
+
+// Rank returns the share of active players slower than wpm, half of its bucket included
+func (h *Histogram) Rank(wpm float64) Standing {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	standing := Standing{WPM: math.Round(wpm*100) / 100, Players: h.players, AsOf: h.builtAt}
+	if h.players == 0 {
+		return standing
+	}
+
+	b := bucket(wpm)
+	below := 0
+	for i := 0; i < b; i++ {
+		below += h.buckets[i]
+	}
+	share := (float64(below) + float64(h.buckets[b])/2) / float64(h.players)
+	standing.Percentile = math.Round(share*1000) / 10
+	return standing
+}
+
+// ForUser ranks the user's recent average WPM, nil when they haven't raced lately
+func (h *Histogram) ForUser(db *gorm.DB, userID uuid.UUID, now time.Time) (*Standing, error) {
+	var avg *float64
+	err := db.Table("results").
+		Select("AVG(wpm)").
+		Where("user_id = ? AND created_at >= ?", userID, now.Add(-ActiveWindow)).
+		Scan(&avg).Error
+	if err != nil || avg == nil {
+		return nil, err
+	}
+	standing := h.Rank(*avg)
+	return &standing, nil
+}
+
+// Start rebuilds the current histogram now and then every RebuildInterval
+func Start(db *gorm.DB) {
+	ticker := time.NewTicker(RebuildInterval)
+	log.Printf("Starting percentile histogram rebuild every %s", RebuildInterval)
+	go func() {
+		for ; true; <-ticker.C {
+			if err := Current.Rebuild(db, time.Now()); err != nil {
+				log.Printf("Percentile rebuild: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,47 @@
+package percentile
+
+import "testing"
+
+func TestRank(t *testing.T) {
+	// 10 players: 2 at 40 WPM, 4 at 60, 3 at 80 and one well past MaxWPM
+	h := &Histogram{players: 10}
+	h.buckets[40] = 2
+	h.buckets[60] = 4
+	h.buckets[80] = 3
+	h.buckets[MaxWPM] = 1
+
+	tests := []struct {
+		name string
+		wpm  float64
+		want float64
+	}{
+		{"slowest of all", 10, 0},
+		{"shares a bucket", 40.7, 10},
+		{"between buckets", 70, 60},
+		{"above the field", 200, 90},
+		{"capped at the last bucket", 500, 95},
+		{"negative counts as zero", -5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.Rank(tt.wpm)
+			if got.Percentile != tt.want {
+				t.Errorf("Rank(%v).Percentile = %v, want %v", tt.wpm, got.Percentile, tt.want)
+			}
+			if got.Players != 10 {
+				t.Errorf("Rank(%v).Players = %d, want 10", tt.wpm, got.Players)
+			}
+		})
+	}
+}
+
+func TestRankEmpty(t *testing.T) {
+	got := (&Histogram{}).Rank(72.345)
+	if got.Percentile != 0 || got.Players != 0 {
+		t.Errorf("Rank() on an empty histogram = %+v, want zero standing", got)
+	}
+	if got.WPM != 72.35 {
+		t.Errorf("Rank().WPM = %v, want 72.35", got.WPM)
+	}
+}
@@ -0,0 +1,226 @@
+package practice
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Nitesh-04/realtime-racing/keystats"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// Window is how far back races are looked at to find weak spots
+	Window = 30 * 24 * time.Hour
+
+	// WordList is what practice texts are built from
+	WordList = prompts.WordList1k
+
+	maxWeakKeys    = 5
+	maxWeakBigrams = 5
+
+	// Minimum presses or samples before a key or bigram can be called weak
+	minPresses = 20
+	minSamples = 5
+)
+
+// Spot is a key or bigram the user struggles with, a Score of 2 is twice their average
+type Spot struct {
+	Kind      string  `json:"kind"`
+	Seq       string  `json:"seq"`
+	ErrorRate float64 `json:"error_rate"`
+	AvgMs     float64 `json:"avg_ms"`
+	Score     float64 `json:"score"`
+}
+
+// WeakSpots finds the letters and pairs the user mistypes or types slowly the most
+func WeakSpots(db *gorm.DB, userID uuid.UUID, since time.Time) ([]Spot, error) {
+	keys, err := keystats.Load(db, userID, models.KeyStatKey, since)
+	if err != nil {
+		return nil, err
+	}
+	bigrams, err := keystats.Load(db, userID, models.KeyStatBigram, since)
+	if err != nil {
+		return nil, err
+	}
+
+	spots := rank(models.KeyStatKey, keys, maxWeakKeys)
+	return append(spots, rank(models.KeyStatBigram, bigrams, maxWeakBigrams)...), nil
+}
+
+func rank(kind string, stats []keystats.Stat, limit int) []Spot {
+	var candidates []keystats.Stat
+	var errSum, msSum float64
+	for _, st := range stats {
+		if !letters(st.Seq) || st.Hits+st.Misses < minPresses || st.Samples < minSamples {
+			continue
+		}
+		candidates = append(candidates, st)
+		errSum += st.ErrorRate
+		msSum += st.AvgMs
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	errMean := errSum / float64(len(candidates))
+	msMean := msSum / float64(len(candidates))
+
+	spots := make([]Spot, 0, len(candidates))
+	for _, st := range candidates {
+		score := 0.0
+		if errMean > 0 {
+			score += st.ErrorRate / errMean
+		} else {
+			score++
+		}
+		if msMean > 0 {
+			score += st.AvgMs / msMean
+		} else {
+			score++
+		}
+		spots = append(spots, Spot{
+			Kind:      kind,
+			Seq:       st.Seq,
+			ErrorRate: st.ErrorRate,
+			AvgMs:     st.AvgMs,
+			Score:     score / 2,
+		})
+	}
+
+	sort.Slice(spots, func(i, j int) bool { return spots[i].Score > spots[j].Score })
+
+	// Only what is actually worse than average is worth drilling
+	n := 0
+	for n < len(spots) && n < limit && spots[n].Score > 1 {
+		n++
+	}
+	return spots[:n]
+}
+
+func letters(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Generate builds a practice text favoring words with the weak spots, stable per seed
+func Generate(spots []Spot, count int, seed int64) (string, error) {
+	words, err := prompts.Words(WordList)
+	if err != nil {
+		return "", err
+	}
+	if len(spots) == 0 {
+		return prompts.Generate(WordList, count, seed)
+	}
+
+	// Each word is weighted by the weak spots it exercises
+	weights := make([]float64, len(words))
+	total := 0.0
+	for i, w := range words {
+		weight := 1.0
+		for _, s := range spots {
+			if n := strings.Count(w, s.Seq); n > 0 {
+				factor := 3.0
+				if s.Kind == models.KeyStatBigram {
+					factor = 5.0
+				}
+				weight += factor * s.Score * float64(n)
+			}
+		}
+		weights[i] = weight
+		total += weight
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	out := make([]string, 0, count)
+	for len(out) < count {
+		pick := r.Float64() * total
+		i := 0
+		for ; i < len(words)-1 && pick >= weights[i]; i++ {
+			pick -= weights[i]
+		}
+		if len(out) > 0 && out[len(out)-1] == words[i] {
+			continue
+		}
+		out = append(out, words[i])
+	}
+	return strings.Join(out, " "), nil
+}
+
+// Point is one week of a weak spot's history
+type Point struct {
+	WeekStart time.Time `json:"week_start"`
+	ErrorRate float64   `json:"error_rate"`
+	AvgMs     float64   `json:"avg_ms"`
+	Presses   int       `json:"presses"`
+}
+
+// Trend is how a weak spot moved over the weeks, a negative Change is better
+type Trend struct {
+	Spot
+	Weeks           []Point `json:"weeks"`
+	ErrorRateChange float64 `json:"error_rate_change"`
+	AvgMsChange     float64 `json:"avg_ms_change"`
+}
+
+// Progress returns the weekly history of each weak spot over the last weeks
+func Progress(db *gorm.DB, userID uuid.UUID, spots []Spot, weeks int) ([]Trend, error) {
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	// Weeks start on Monday
+	thisWeek := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	since := thisWeek.AddDate(0, 0, -7*(weeks-1))
+
+	trends := make([]Trend, 0, len(spots))
+	for _, spot := range spots {
+		var rows []struct {
+			WeekStart time.Time
+			Hits      int
+			Misses    int
+			Samples   int
+			TotalMs   int64
+		}
+		err := db.Model(&models.KeyStat{}).
+			Select("date_trunc('week', day) AS week_start, SUM(hits) AS hits, SUM(misses) AS misses, "+
+				"SUM(samples) AS samples, SUM(total_ms) AS total_ms").
+			Where("user_id = ? AND kind = ? AND seq = ? AND day >= ?", userID, spot.Kind, spot.Seq, since).
+			Group("week_start").
+			Order("week_start ASC").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		trend := Trend{Spot: spot, Weeks: []Point{}}
+		for _, r := range rows {
+			p := Point{WeekStart: r.WeekStart, Presses: r.Hits + r.Misses}
+			if p.Presses > 0 {
+				p.ErrorRate = round2(float64(r.Misses) / float64(p.Presses) * 100)
+			}
+			if r.Samples > 0 {
+				p.AvgMs = round2(float64(r.TotalMs) / float64(r.Samples))
+			}
+			trend.Weeks = append(trend.Weeks, p)
+		}
+		if n := len(trend.Weeks); n > 1 {
+			trend.ErrorRateChange = round2(trend.Weeks[n-1].ErrorRate - trend.Weeks[0].ErrorRate)
+			trend.AvgMsChange = round2(trend.Weeks[n-1].AvgMs - trend.Weeks[0].AvgMs)
+		}
+		trends = append(trends, trend)
+	}
+	return trends, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
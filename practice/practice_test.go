@@ -0,0 +1,127 @@
+package practice
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Nitesh-04/realtime-racing/keystats"
+	"github.com/Nitesh-04/realtime-racing/models"
+)
+
+func TestRank(t *testing.T) {
+	stat := func(seq string, errorRate, avgMs float64) keystats.Stat {
+		return keystats.Stat{Seq: seq, Hits: 30, ErrorRate: errorRate, AvgMs: avgMs, Samples: 10}
+	}
+
+	tests := []struct {
+		name  string
+		stats []keystats.Stat
+		limit int
+		want  []string
+	}{
+		{
+			name:  "only worse than average",
+			stats: []keystats.Stat{stat("a", 10, 200), stat("b", 2, 100), stat("c", 0, 150)},
+			limit: 5,
+			want:  []string{"a"},
+		},
+		{
+			name:  "worst first",
+			stats: []keystats.Stat{stat("a", 4, 150), stat("b", 8, 200), stat("c", 0, 100), stat("d", 0, 100)},
+			limit: 5,
+			want:  []string{"b", "a"},
+		},
+		{
+			name:  "capped at the limit",
+			stats: []keystats.Stat{stat("a", 4, 150), stat("b", 8, 200), stat("c", 0, 100), stat("d", 0, 100)},
+			limit: 1,
+			want:  []string{"b"},
+		},
+		{
+			name: "too little data and non-letters are skipped",
+			stats: []keystats.Stat{
+				stat("a", 1, 100),
+				stat("b", 1, 100),
+				{Seq: "c", Hits: 5, ErrorRate: 50, AvgMs: 500, Samples: 10},
+				{Seq: "d", Hits: 30, ErrorRate: 50, AvgMs: 500, Samples: 1},
+				stat(";", 50, 500),
+			},
+			limit: 5,
+			want:  nil,
+		},
+		{
+			name:  "all equal has nothing to drill",
+			stats: []keystats.Stat{stat("a", 0, 0), stat("b", 0, 0)},
+			limit: 5,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range rank(models.KeyStatKey, tt.stats, tt.limit) {
+				got = append(got, s.Seq)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	spots := []Spot{{Kind: models.KeyStatKey, Seq: "z", Score: 2}}
+
+	tests := []struct {
+		name  string
+		spots []Spot
+		count int
+	}{
+		{"without weak spots", nil, 50},
+		{"with weak spots", spots, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := Generate(tt.spots, tt.count, 42)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if n := len(strings.Fields(text)); n != tt.count {
+				t.Errorf("Generate() has %d words, want %d", n, tt.count)
+			}
+			again, _ := Generate(tt.spots, tt.count, 42)
+			if again != text {
+				t.Errorf("Generate() isn't stable for the same seed")
+			}
+		})
+	}
+}
+
+func TestGenerateFavorsWeakSpots(t *testing.T) {
+	spots := []Spot{{Kind: models.KeyStatKey, Seq: "z", Score: 2}}
+
+	withZ := func(text string) int {
+		n := 0
+		for _, w := range strings.Fields(text) {
+			if strings.Contains(w, "z") {
+				n++
+			}
+		}
+		return n
+	}
+
+	plain, err := Generate(nil, 1000, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drill, err := Generate(spots, 1000, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withZ(drill) <= 2*withZ(plain) {
+		t.Errorf("practice text has %d words with the weak key, plain text %d", withZ(drill), withZ(plain))
+	}
+}
@@ -0,0 +1,173 @@
+package prompts
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+)
+
+// Score boundaries between the difficulty buckets
+const (
+	easyBelow   = 28.0
+	mediumBelow = 40.0
+)
+
+// commonBigrams are the ~170 most frequent letter pairs in English text
+var commonBigrams = map[string]bool{}
+
+func init() {
+	for _, b := range strings.Fields(`
+		th he in er an re on at en nd ti es or te of ed is it al ar st to nt ng se
+		ha as ou io le ve co me de hi ri ro ic ne ea ra ce li ch ll be ma si om ur
+		ca el ta la ns di fo ho pe ec pr no ct us ac ot il tr ly nc et ut ss so rs
+		un lo wa ge ie wh ee wi em ad ol rt po we na ul ni ts mo ow pa im mi ai sh
+		ir su id os iv ia am fi ci vi pl ig tu ev ld ry mp fe bl ab gh ty op wo sa
+		ay ex ke fr oo av ag if ap gr od bo sp rd do uc bu ei ov by rm ep tt oc fa
+		ef cu rn sc gi da yo cr cl du ga qu ue ff ba ey ls va um pp ua up
+	`) {
+		commonBigrams[b] = true
+	}
+}
+
+// fingers maps each QWERTY key to its finger, 0-7 from left pinky to right pinky
+var fingers = map[rune]int{}
+
+func init() {
+	columns := []struct {
+		keys   string
+		finger int
+	}{
+		{"1qaz`", 0}, {"2wsx", 1}, {"3edc", 2}, {"4rfv5tgb", 3},
+		{"6yhn7ujm", 4}, {"8ik,", 5}, {"9ol.", 6}, {"0p;/-['=]\\", 7},
+	}
+	for _, col := range columns {
+		for _, k := range col.keys {
+			fingers[k] = col.finger
+		}
+	}
+}
+
+// Analysis holds the features a difficulty score is built from
+type Analysis struct {
+	AvgWordLength   float64 `json:"avg_word_length"`
+	LongWordRatio   float64 `json:"long_word_ratio"`
+	RareBigramRatio float64 `json:"rare_bigram_ratio"`
+	SymbolRatio     float64 `json:"symbol_ratio"`
+	CapitalRatio    float64 `json:"capital_ratio"`
+	SameFingerRatio float64 `json:"same_finger_ratio"`
+	Score           float64 `json:"score"`
+}
+
+// Analyze scores how hard a passage is to type, from 0 (trivial) to 100
+func Analyze(text string) Analysis {
+	var a Analysis
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return a
+	}
+
+	letters, long := 0, 0
+	for _, w := range words {
+		n := 0
+		for _, r := range w {
+			if unicode.IsLetter(r) {
+				n++
+			}
+		}
+		letters += n
+		if n >= 8 {
+			long++
+		}
+	}
+	a.AvgWordLength = float64(letters) / float64(len(words))
+	a.LongWordRatio = float64(long) / float64(len(words))
+
+	var bigrams, rare, sameFinger, transitions int
+	var symbols, capitals, chars int
+	var prev rune
+
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			prev = 0
+			continue
+		}
+		chars++
+
+		switch {
+		case unicode.IsUpper(r):
+			capitals++
+		case unicode.IsDigit(r), unicode.IsPunct(r), unicode.IsSymbol(r):
+			symbols++
+		}
+
+		lower := unicode.ToLower(r)
+		if prev != 0 {
+			if unicode.IsLetter(prev) && unicode.IsLetter(lower) {
+				bigrams++
+				if !commonBigrams[string([]rune{prev, lower})] {
+					rare++
+				}
+			}
+
+			f1, ok1 := fingers[prev]
+			f2, ok2 := fingers[lower]
+			if ok1 && ok2 {
+				transitions++
+				if f1 == f2 && prev != lower {
+					sameFinger++
+				}
+			}
+		}
+		prev = lower
+	}
+
+	if bigrams > 0 {
+		a.RareBigramRatio = float64(rare) / float64(bigrams)
+	}
+	if transitions > 0 {
+		a.SameFingerRatio = float64(sameFinger) / float64(transitions)
+	}
+	if chars > 0 {
+		a.SymbolRatio = float64(symbols) / float64(chars)
+		a.CapitalRatio = float64(capitals) / float64(chars)
+	}
+
+	// Each feature is scaled so that a very hard passage lands near 1
+	score := 25*clamp((a.AvgWordLength-3.5)/4) +
+		10*clamp(a.LongWordRatio/0.3) +
+		20*clamp(a.RareBigramRatio/0.3) +
+		20*clamp(a.SymbolRatio/0.15) +
+		10*clamp(a.CapitalRatio/0.1) +
+		15*clamp(a.SameFingerRatio/0.12)
+
+	a.Score = math.Round(score*10) / 10
+	return a
+}
+
+// DifficultyFor buckets a score into easy, medium or hard
+func DifficultyFor(score float64) models.PromptDifficulty {
+	switch {
+	case score < easyBelow:
+		return models.PromptDifficultyEasy
+	case score < mediumBelow:
+		return models.PromptDifficultyMedium
+	}
+	return models.PromptDifficultyHard
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// IsPunctuated reports whether the text has anything beyond lowercase letters and spaces
+func IsPunctuated(text string) bool {
+	for _, r := range text {
+		if unicode.IsUpper(r) || unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package prompts
+
+import (
+	"testing"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+)
+
+func TestDifficultyFor(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  models.PromptDifficulty
+	}{
+		{0, models.PromptDifficultyEasy},
+		{easyBelow - 0.1, models.PromptDifficultyEasy},
+		{easyBelow, models.PromptDifficultyMedium},
+		{mediumBelow - 0.1, models.PromptDifficultyMedium},
+		{mediumBelow, models.PromptDifficultyHard},
+		{100, models.PromptDifficultyHard},
+	}
+
+	for _, tt := range tests {
+		if got := DifficultyFor(tt.score); got != tt.want {
+			t.Errorf("DifficultyFor(%v) = %v, want %v", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want models.PromptDifficulty
+	}{
+		{"short common words", "the cat sat on the mat and then it ran to the den", models.PromptDifficultyEasy},
+		{"long words and symbols", "Quixotic Zbigniew's (2019) juxtaposition: \"Pfft!\" {xylophonic} & [Kvetch]; #Syzygy @Fjord", models.PromptDifficultyHard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Analyze(tt.text)
+			if a.Score < 0 || a.Score > 100 {
+				t.Errorf("Analyze().Score = %v, want within 0-100", a.Score)
+			}
+			if got := DifficultyFor(a.Score); got != tt.want {
+				t.Errorf("Analyze() scored %v (%v), want %v", a.Score, got, tt.want)
+			}
+		})
+	}
+
+	if a := Analyze("   "); a != (Analysis{}) {
+		t.Errorf("Analyze() of blank text = %+v, want zero", a)
+	}
+}
+
+func TestIsPunctuated(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"just plain words", false},
+		{"", false},
+		{"Capital letter", true},
+		{"a comma, here", true},
+		{"room 101", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsPunctuated(tt.text); got != tt.want {
+			t.Errorf("IsPunctuated(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// Word lists the generator can draw from, ordered by frequency
+const (
+	WordList200 = "english_200"
+	WordList1k  = "english_1k"
+	WordList10k = "english_10k"
+
+	// GeneratorWPM is the speed generated text is sized for when a room asks
+	// for enough words to fill its duration
+	GeneratorWPM = 150
+
+	// MaxGeneratedWords caps how long a generated prompt can get
+	MaxGeneratedWords = 1000
+)
+
+var WordLists = []string{WordList200, WordList1k, WordList10k}
+
+//go:embed words/*.txt
+var wordFiles embed.FS
+
+var (
+	wordListsMu sync.Mutex
+	wordLists   = map[string][]string{}
+)
+
+// Words returns the named word list, loading it on first use
+func Words(name string) ([]string, error) {
+	wordListsMu.Lock()
+	defer wordListsMu.Unlock()
+
+	if words, ok := wordLists[name]; ok {
+		return words, nil
+	}
+
+	var words []string
+	var err error
+
+	switch name {
+	case WordList200, WordList1k, WordList10k:
+		var f []byte
+		f, err = wordFiles.ReadFile("words/" + name + ".txt")
+		if err == nil {
+			words = strings.Fields(string(f))
+		}
+	default:
+		return nil, fmt.Errorf("unknown word list %q", name)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("word list %s: %v", name, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("word list %s is empty", name)
+	}
+
+	wordLists[name] = words
+	return words, nil
+}
+
+// WordsForDuration is how many words fill the given seconds at GeneratorWPM
+func WordsForDuration(seconds int) int {
+	return (GeneratorWPM*seconds + 59) / 60
+}
+
+// Generate builds count random words from the list, the same seed gives the same text
+func Generate(list string, count int, seed int64) (string, error) {
+	if count < 1 || count > MaxGeneratedWords {
+		return "", fmt.Errorf("word count must be between 1 and %d", MaxGeneratedWords)
+	}
+
+	words, err := Words(list)
+	if err != nil {
+		return "", err
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	out := make([]string, 0, count)
+	for len(out) < count {
+		w := words[r.Intn(len(words))]
+		// Avoid the same word twice in a row
+		if len(words) > 1 && len(out) > 0 && out[len(out)-1] == w {
+			continue
+		}
+		out = append(out, w)
+	}
+	return strings.Join(out, " "), nil
+}
@@ -0,0 +1,105 @@
+package prompts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		list string
+		want int
+	}{
+		{WordList200, 200},
+		{WordList1k, 1000},
+		{WordList10k, 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			words, err := Words(tt.list)
+			if err != nil {
+				t.Fatalf("Words(%q) error = %v", tt.list, err)
+			}
+			if len(words) != tt.want {
+				t.Errorf("Words(%q) has %d words, want %d", tt.list, len(words), tt.want)
+			}
+		})
+	}
+
+	if _, err := Words("english_none"); err == nil {
+		t.Error("Words() of an unknown list should fail")
+	}
+}
+
+func TestWordsForDuration(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    int
+	}{
+		{15, 38},
+		{30, 75},
+		{60, 150},
+		{1, 3},
+	}
+
+	for _, tt := range tests {
+		if got := WordsForDuration(tt.seconds); got != tt.want {
+			t.Errorf("WordsForDuration(%d) = %d, want %d", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		count   int
+		wantErr bool
+	}{
+		{"short", WordList200, 1, false},
+		{"longest allowed", WordList1k, MaxGeneratedWords, false},
+		{"no words", WordList200, 0, true},
+		{"too many words", WordList200, MaxGeneratedWords + 1, true},
+		{"unknown list", "english_none", 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := Generate(tt.list, tt.count, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			words := strings.Fields(text)
+			if len(words) != tt.count {
+				t.Errorf("Generate() has %d words, want %d", len(words), tt.count)
+			}
+			list, _ := Words(tt.list)
+			for i, w := range words {
+				if !slices.Contains(list, w) {
+					t.Errorf("Generate() word %q isn't in %s", w, tt.list)
+				}
+				if i > 0 && words[i-1] == w {
+					t.Errorf("Generate() repeats %q at %d", w, i)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateSeed(t *testing.T) {
+	a, _ := Generate(WordList1k, 50, 7)
+	b, _ := Generate(WordList1k, 50, 7)
+	c, _ := Generate(WordList1k, 50, 8)
+	if a != b {
+		t.Error("Generate() gave different text for the same seed")
+	}
+	if a == c {
+		t.Error("Generate() gave the same text for different seeds")
+	}
+}
@@ -0,0 +1,419 @@
+package prompts
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"gorm.io/gorm"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatText = "text"
+
+	// MinLength is the shortest passage accepted into the library, in
+	// characters
+	MinLength = 20
+
+	// MaxLength is the longest text users can submit or race on privately
+	MaxLength = 1000
+)
+
+var Formats = []string{FormatJSON, FormatCSV, FormatText}
+
+// csvHeader is the column order for CSV import and export, tags are "|" separated
+var csvHeader = []string{"text", "source", "language", "tags", "difficulty", "active", "mode", "code_language", "status"}
+
+// Record is a prompt as it appears in an import or export file
+type Record struct {
+	Text       string   `json:"text"`
+	Source     string   `json:"source,omitempty"`
+	Language   string   `json:"language,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+	Active     *bool    `json:"active,omitempty"`
+
+	Mode         string `json:"mode,omitempty"`
+	CodeLanguage string `json:"code_language,omitempty"`
+
+	// Review status, records without one are imported as approved
+	Status string `json:"status,omitempty"`
+}
+
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportReport struct {
+	Total      int        `json:"total"`
+	Created    int        `json:"created"`
+	Duplicates int        `json:"duplicates"`
+	Errors     []RowError `json:"errors"`
+}
+
+// CheckLength validates the length of user provided text
+func CheckLength(text string) error {
+	n := Length(text)
+	if n < MinLength {
+		return fmt.Errorf("text must be at least %d characters long", MinLength)
+	}
+	if n > MaxLength {
+		return fmt.Errorf("text must be at most %d characters long", MaxLength)
+	}
+	return nil
+}
+
+// FormatFromName guesses the format from a file extension
+func FormatFromName(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return FormatJSON
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	}
+	return FormatText
+}
+
+// Parse reads records in the given format, unreadable rows are reported, numbered from 1
+func Parse(r io.Reader, format string) ([]Record, []RowError, error) {
+	switch format {
+	case FormatJSON:
+		var records []Record
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		return records, nil, nil
+
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+
+		header, err := reader.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("missing CSV header: %v", err)
+		}
+		columns := make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := columns["text"]; !ok {
+			return nil, nil, fmt.Errorf("CSV header must have a text column")
+		}
+
+		var records []Record
+		var rowErrors []RowError
+		for row := 1; ; row++ {
+			fields, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				rowErrors = append(rowErrors, RowError{Row: row, Error: err.Error()})
+				records = append(records, Record{})
+				continue
+			}
+
+			get := func(name string) string {
+				if i, ok := columns[name]; ok && i < len(fields) {
+					return strings.TrimSpace(fields[i])
+				}
+				return ""
+			}
+
+			record := Record{
+				Source:       get("source"),
+				Language:     get("language"),
+				Difficulty:   get("difficulty"),
+				Mode:         get("mode"),
+				CodeLanguage: get("code_language"),
+				Status:       get("status"),
+			}
+			// Code keeps its indentation, normalization takes care of the rest
+			if i := columns["text"]; i < len(fields) {
+				record.Text = fields[i]
+			}
+			if tags := get("tags"); tags != "" {
+				record.Tags = strings.Split(tags, "|")
+			}
+			if active := get("active"); active != "" {
+				b, err := strconv.ParseBool(active)
+				if err != nil {
+					rowErrors = append(rowErrors, RowError{Row: row, Error: "active must be true or false"})
+					records = append(records, Record{})
+					continue
+				}
+				record.Active = &b
+			}
+			records = append(records, record)
+		}
+		return records, rowErrors, nil
+
+	case FormatText:
+		var records []Record
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				records = append(records, Record{Text: line})
+			}
+		}
+		return records, nil, scanner.Err()
+	}
+
+	return nil, nil, fmt.Errorf("unknown format %q", format)
+}
+
+// ToPrompt validates and normalizes a record into a library prompt
+func (r Record) ToPrompt() (models.Prompt, error) {
+	mode := models.PromptMode(strings.ToLower(strings.TrimSpace(r.Mode)))
+	if mode == "" {
+		mode = models.PromptModeText
+	}
+
+	prompt := models.Prompt{
+		Text:         NormalizeFor(mode, r.Text),
+		Mode:         mode,
+		CodeLanguage: strings.ToLower(strings.TrimSpace(r.CodeLanguage)),
+		Source:       strings.TrimSpace(r.Source),
+		Language:     strings.TrimSpace(r.Language),
+		Tags:         r.Tags,
+		Difficulty:   models.PromptDifficulty(strings.ToLower(r.Difficulty)),
+		Status:       models.PromptStatusApproved,
+	}
+	prompt.DifficultyScore = Analyze(prompt.Text).Score
+	prompt.Punctuated = IsPunctuated(prompt.Text)
+	if prompt.Language == "" {
+		prompt.Language = "en"
+	}
+	language, err := NormalizeLanguage(prompt.Language)
+	if err != nil {
+		return prompt, err
+	}
+	prompt.Language = language
+	if prompt.Difficulty == "" {
+		prompt.Difficulty = DifficultyFor(prompt.DifficultyScore)
+	}
+	if status := strings.ToLower(strings.TrimSpace(r.Status)); status != "" {
+		prompt.Status = models.PromptStatus(status)
+	}
+	// Only approved prompts go live unless the row says otherwise
+	prompt.Active = prompt.Status == models.PromptStatusApproved
+	if r.Active != nil {
+		prompt.Active = *r.Active
+	}
+
+	if !prompt.Mode.Valid() {
+		return prompt, fmt.Errorf("mode must be text or code")
+	}
+	if prompt.Mode == models.PromptModeCode && prompt.CodeLanguage == "" {
+		return prompt, fmt.Errorf("code prompts need a code_language")
+	}
+	if prompt.Mode == models.PromptModeText {
+		prompt.CodeLanguage = ""
+	}
+	if Length(prompt.Text) < MinLength {
+		return prompt, fmt.Errorf("text must be at least %d characters long", MinLength)
+	}
+	if !prompt.Difficulty.Valid() {
+		return prompt, fmt.Errorf("difficulty must be one of easy, medium or hard")
+	}
+	if !prompt.Status.Valid() {
+		return prompt, fmt.Errorf("status must be one of pending, approved or rejected")
+	}
+	if prompt.Active && prompt.Status != models.PromptStatusApproved {
+		return prompt, fmt.Errorf("only approved prompts can be active")
+	}
+
+	// Rejected prompts don't hold on to their text, see Backfill
+	if prompt.Status != models.PromptStatusRejected {
+		hash := HashFor(prompt.Mode, prompt.Text)
+		prompt.Hash = &hash
+	}
+	return prompt, nil
+}
+
+// Import inserts the valid records whose normalized text isn't in the library yet
+func Import(db *gorm.DB, records []Record, parseErrors []RowError) (ImportReport, error) {
+	report := ImportReport{Total: len(records), Errors: parseErrors}
+
+	failed := make(map[int]bool, len(parseErrors))
+	for _, e := range parseErrors {
+		failed[e.Row] = true
+	}
+
+	var candidates []models.Prompt
+	var hashes []string
+	seen := make(map[string]bool)
+
+	for i, record := range records {
+		row := i + 1
+		if failed[row] {
+			continue
+		}
+
+		prompt, err := record.ToPrompt()
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Row: row, Error: err.Error()})
+			continue
+		}
+		if prompt.Hash == nil {
+			candidates = append(candidates, prompt)
+			continue
+		}
+		if seen[*prompt.Hash] {
+			report.Duplicates++
+			continue
+		}
+		seen[*prompt.Hash] = true
+		candidates = append(candidates, prompt)
+		hashes = append(hashes, *prompt.Hash)
+	}
+
+	if len(candidates) == 0 {
+		return report, nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing []string
+		if err := tx.Model(&models.Prompt{}).Where("hash IN ?", hashes).Pluck("hash", &existing).Error; err != nil {
+			return err
+		}
+		known := make(map[string]bool, len(existing))
+		for _, h := range existing {
+			known[h] = true
+		}
+
+		var fresh []models.Prompt
+		for _, p := range candidates {
+			if p.Hash != nil && known[*p.Hash] {
+				report.Duplicates++
+				continue
+			}
+			fresh = append(fresh, p)
+		}
+
+		if len(fresh) > 0 {
+			if err := tx.CreateInBatches(&fresh, 200).Error; err != nil {
+				return err
+			}
+		}
+		report.Created = len(fresh)
+		return nil
+	})
+
+	return report, err
+}
+
+// Export writes the whole library, text exports only hold approved text passages
+func Export(db *gorm.DB, w io.Writer, format string) error {
+	var list []models.Prompt
+	if err := db.Order("created_at ASC, id ASC").Find(&list).Error; err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		records := make([]Record, 0, len(list))
+		for _, p := range list {
+			active := p.Active
+			records = append(records, Record{
+				Text:       p.Text,
+				Source:     p.Source,
+				Language:   p.Language,
+				Tags:       p.Tags,
+				Difficulty: string(p.Difficulty),
+				Active:     &active,
+
+				Mode:         string(p.Mode),
+				CodeLanguage: p.CodeLanguage,
+				Status:       string(p.Status),
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, p := range list {
+			err := writer.Write([]string{
+				p.Text,
+				p.Source,
+				p.Language,
+				strings.Join(p.Tags, "|"),
+				string(p.Difficulty),
+				strconv.FormatBool(p.Active),
+				string(p.Mode),
+				p.CodeLanguage,
+				string(p.Status),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case FormatText:
+		bw := bufio.NewWriter(w)
+		for _, p := range list {
+			if p.Mode == models.PromptModeCode || p.Status != models.PromptStatusApproved {
+				continue
+			}
+			if _, err := bw.WriteString(p.Text + "\n"); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+	}
+
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// Backfill recomputes the hash and difficulty of prompts stored before they existed
+func Backfill(db *gorm.DB) error {
+	if err := db.Model(&models.Prompt{}).
+		Where("status = ? AND hash IS NOT NULL", models.PromptStatusRejected).
+		Update("hash", nil).Error; err != nil {
+		return err
+	}
+
+	var list []models.Prompt
+	if err := db.Where("status <> ?", models.PromptStatusRejected).Find(&list).Error; err != nil {
+		return err
+	}
+
+	for _, p := range list {
+		hash := HashFor(p.Mode, p.Text)
+		score := Analyze(p.Text).Score
+		punctuated := IsPunctuated(p.Text)
+
+		if p.Hash != nil && *p.Hash == hash && p.DifficultyScore == score && p.Punctuated == punctuated {
+			continue
+		}
+
+		updates := map[string]interface{}{
+			"hash":             hash,
+			"difficulty_score": score,
+			"punctuated":       punctuated,
+		}
+		if p.DifficultyScore == 0 {
+			updates["difficulty"] = DifficultyFor(score)
+		}
+		if err := db.Model(&p).Updates(updates).Error; err != nil {
+			return fmt.Errorf("prompt %s: %v", p.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/Nitesh-04/realtime-racing/constants"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Filter narrows down which library prompts a room can get, empty fields match everything
+type Filter struct {
+	Language   string
+	Difficulty models.PromptDifficulty
+	Tag        string
+	Punctuated *bool
+
+	Mode         models.PromptMode
+	CodeLanguage string
+}
+
+var ErrNoPrompt = fmt.Errorf("no active prompt matches the filter")
+
+func (f Filter) apply(query *gorm.DB) *gorm.DB {
+	if f.Language != "" {
+		// "zh" also matches regional and script variants like "zh-Hant"
+		query = query.Where("language = ? OR language LIKE ?", f.Language, f.Language+"-%")
+	}
+	if f.Difficulty != "" {
+		query = query.Where("difficulty = ?", f.Difficulty)
+	}
+	if f.Mode != "" {
+		query = query.Where("mode = ?", f.Mode)
+	}
+	if f.CodeLanguage != "" {
+		query = query.Where("code_language = ?", f.CodeLanguage)
+	}
+	if f.Punctuated != nil {
+		query = query.Where("punctuated = ?", *f.Punctuated)
+	}
+	if f.Tag != "" {
+		// Tags are stored as a JSON array
+		tag, _ := json.Marshal([]string{f.Tag})
+		query = query.Where("tags::jsonb @> ?", string(tag))
+	}
+	return query
+}
+
+// Random picks an active prompt matching the filter
+func Random(db *gorm.DB, f Filter) (models.Prompt, error) {
+	var prompt models.Prompt
+	err := f.apply(db.Where("active = true")).
+		Order("RANDOM()").
+		Limit(1).
+		Find(&prompt).Error
+	if err != nil {
+		return prompt, err
+	}
+	if prompt.ID == uuid.Nil {
+		return prompt, ErrNoPrompt
+	}
+	return prompt, nil
+}
+
+// Seed adds the built-in prompts that aren't in the library yet, by hash
+func Seed(db *gorm.DB) error {
+	var builtins []models.Prompt
+	for _, p := range constants.Prompts {
+		builtins = append(builtins, models.Prompt{Text: p.Prompt, Mode: models.PromptModeText, Tags: []string{"story"}})
+	}
+	for _, p := range constants.PunctuatedPrompts {
+		builtins = append(builtins, models.Prompt{Text: p.Prompt, Mode: models.PromptModeText, Tags: []string{"story"}})
+	}
+	for _, p := range constants.LocalizedPrompts {
+		builtins = append(builtins, models.Prompt{Text: Normalize(p.Prompt), Mode: models.PromptModeText, Language: p.Language, Tags: []string{"story"}})
+	}
+	for _, p := range constants.CodePrompts {
+		builtins = append(builtins, models.Prompt{
+			Text:         NormalizeCode(p.Code),
+			Mode:         models.PromptModeCode,
+			CodeLanguage: p.Language,
+			Tags:         []string{"code"},
+		})
+	}
+
+	hashes := make([]string, 0, len(builtins))
+	for _, p := range builtins {
+		hashes = append(hashes, HashFor(p.Mode, p.Text))
+	}
+
+	var existing []string
+	if err := db.Model(&models.Prompt{}).Where("hash IN ?", hashes).Pluck("hash", &existing).Error; err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, h := range existing {
+		known[h] = true
+	}
+
+	var rows []models.Prompt
+	for i, p := range builtins {
+		hash := hashes[i]
+		if known[hash] {
+			continue
+		}
+		known[hash] = true
+
+		score := Analyze(p.Text).Score
+		p.Hash = &hash
+		p.Source = "built-in"
+		if p.Language == "" {
+			p.Language = "en"
+		}
+		p.Difficulty = DifficultyFor(score)
+		p.DifficultyScore = score
+		p.Punctuated = IsPunctuated(p.Text)
+		p.Active = true
+		p.Status = models.PromptStatusApproved
+		rows = append(rows, p)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := db.Create(&rows).Error; err != nil {
+		return err
+	}
+
+	log.Printf("Seeded prompt library with %d built-in prompts", len(rows))
+	return nil
+}
@@ -0,0 +1,146 @@
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// tabWidth is how many spaces a tab in a code prompt expands to
+const tabWidth = 4
+
+// Typographic characters folded to what can be typed on a plain keyboard
+var replacer = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'",
+	"\u201c", "\"", "\u201d", "\"", "\u201e", "\"", "\u201f", "\"",
+	"\u2013", "-", "\u2014", "-", "\u2212", "-",
+	"\u2026", "...",
+	"\u00a0", " ", "\u2009", " ", "\u202f", " ",
+	"\u200b", "", "\ufeff", "",
+)
+
+// Normalize composes to NFC, folds typographic punctuation and collapses whitespace
+func Normalize(text string) string {
+	text = replacer.Replace(norm.NFC.String(text))
+
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Hash identifies a prompt by its normalized text, case included
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(Normalize(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeCode cleans up a code snippet while keeping its layout
+func NormalizeCode(text string) string {
+	text = norm.NFC.String(text)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.ReplaceAll(text, "\t", strings.Repeat(" ", tabWidth))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, line)
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i, line := range lines {
+			if len(line) >= indent {
+				lines[i] = line[indent:]
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// NormalizeFor normalizes the text the way prompts of the given mode are stored
+func NormalizeFor(mode models.PromptMode, text string) string {
+	if mode == models.PromptModeCode {
+		return NormalizeCode(text)
+	}
+	return Normalize(text)
+}
+
+// HashFor is Hash for prompts of the given mode, code is hashed after NormalizeCode
+func HashFor(mode models.PromptMode, text string) string {
+	if mode != models.PromptModeCode {
+		return Hash(text)
+	}
+	sum := sha256.Sum256([]byte(NormalizeCode(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// AnyLanguage can be asked for instead of a language tag
+const AnyLanguage = "any"
+
+// NormalizeLanguage validates a BCP 47 tag and returns its canonical form
+func NormalizeLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", fmt.Errorf("language is required")
+	}
+	t, err := language.Parse(strings.ReplaceAll(tag, "_", "-"))
+	if err != nil {
+		return "", fmt.Errorf("invalid language %q", tag)
+	}
+	return t.String(), nil
+}
+
+// Length is the number of grapheme clusters in the text
+func Length(text string) int {
+	return uniseg.GraphemeClusterCount(text)
+}
+
+// markup matches HTML-like tags, which have no place in a text passage
+var markup = regexp.MustCompile(`<[^<>]*>`)
+
+// Sanitize strips markup from user text passages and normalizes it for its mode
+func Sanitize(mode models.PromptMode, text string) string {
+	if mode != models.PromptModeCode {
+		text = markup.ReplaceAllString(text, " ")
+	}
+	return NormalizeFor(mode, text)
+}
@@ -0,0 +1,121 @@
+package prompts
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "the quick fox", "the quick fox"},
+		{"collapses whitespace", "  the\tquick \n\n fox  ", "the quick fox"},
+		{"folds typographic quotes", "\u201cit\u2019s\u201d", "\"it's\""},
+		{"folds dashes and ellipsis", "wait\u2014what\u2026", "wait-what..."},
+		{"drops zero width characters", "zero\u200bwidth", "zerowidth"},
+		{"composes to NFC", "cafe\u0301", "caf\u00e9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"same text", "the quick fox", "the quick fox", true},
+		{"whitespace doesn't matter", "the  quick\nfox", "the quick fox", true},
+		{"typographic quotes don't matter", "it\u2019s", "it's", true},
+		{"decomposed accents don't matter", "cafe\u0301", "caf\u00e9", true},
+		{"case matters", "The quick fox", "the quick fox", false},
+		{"punctuation matters", "the quick fox.", "the quick fox", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := Hash(tt.a) == Hash(tt.b); same != tt.same {
+				t.Errorf("Hash(%q) == Hash(%q) is %v, want %v", tt.a, tt.b, same, tt.same)
+			}
+		})
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"en", "en", false},
+		{"EN", "en", false},
+		{"zh_hant", "zh-Hant", false},
+		{" pt-br ", "pt-BR", false},
+		{"", "", true},
+		{"not a language", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizeLanguage(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeLanguage(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeLanguage(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordStatus(t *testing.T) {
+	text := "a passage long enough to go into the library"
+	yes, no := true, false
+	tests := []struct {
+		name       string
+		status     string
+		active     *bool
+		want       string
+		wantActive bool
+		wantErr    bool
+	}{
+		{"default", "", nil, "approved", true, false},
+		{"approved", "approved", nil, "approved", true, false},
+		{"pending", "Pending", nil, "pending", false, false},
+		{"rejected", "rejected", nil, "rejected", false, false},
+		{"approved but inactive", "approved", &no, "approved", false, false},
+		{"pending and inactive", "pending", &no, "pending", false, false},
+		{"pending but active", "pending", &yes, "", false, true},
+		{"rejected but active", "rejected", &yes, "", false, true},
+		{"unknown", "deleted", nil, "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, err := Record{Text: text, Status: tt.status, Active: tt.active}.ToPrompt()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToPrompt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(prompt.Status) != tt.want {
+				t.Errorf("ToPrompt() status = %q, want %q", prompt.Status, tt.want)
+			}
+			if prompt.Active != tt.wantActive {
+				t.Errorf("ToPrompt() active = %v, want %v", prompt.Active, tt.wantActive)
+			}
+			// Rejected prompts leave their text free for a new submission
+			if (prompt.Hash == nil) != (tt.want == "rejected") {
+				t.Errorf("ToPrompt() hash = %v for status %q", prompt.Hash, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package prompts
+
+import (
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordResult folds a result into its library prompt's stats
+func RecordResult(tx *gorm.DB, result models.Results) error {
+	if result.PromptID == nil {
+		return nil
+	}
+
+	var stat models.PromptStat
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("prompt_id = ?", *result.PromptID).
+		Limit(1).
+		Find(&stat).Error
+	if err != nil {
+		return err
+	}
+
+	setAt := result.CreatedAt
+	if setAt.IsZero() {
+		setAt = time.Now()
+	}
+
+	if stat.PromptID == uuid.Nil {
+		stat = models.PromptStat{
+			PromptID:       *result.PromptID,
+			Races:          1,
+			BestWPM:        result.WPM,
+			TotalWPM:       int64(result.WPM),
+			RecordHolderID: &result.UserID,
+			RecordSetAt:    &setAt,
+		}
+		// Another race on the same prompt may have created the row meanwhile,
+		// the record only moves to this result if it beat the stored best
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "prompt_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"races":            gorm.Expr("prompt_stats.races + 1"),
+				"total_wpm":        gorm.Expr("prompt_stats.total_wpm + excluded.total_wpm"),
+				"best_wpm":         gorm.Expr("GREATEST(prompt_stats.best_wpm, excluded.best_wpm)"),
+				"record_holder_id": gorm.Expr("CASE WHEN excluded.best_wpm > prompt_stats.best_wpm THEN excluded.record_holder_id ELSE prompt_stats.record_holder_id END"),
+				"record_set_at":    gorm.Expr("CASE WHEN excluded.best_wpm > prompt_stats.best_wpm THEN excluded.record_set_at ELSE prompt_stats.record_set_at END"),
+				"updated_at":       time.Now(),
+			}),
+		}).Create(&stat).Error
+	}
+
+	updates := map[string]interface{}{
+		"races":     gorm.Expr("races + 1"),
+		"total_wpm": gorm.Expr("total_wpm + ?", result.WPM),
+	}
+	if result.WPM > stat.BestWPM {
+		updates["best_wpm"] = result.WPM
+		updates["record_holder_id"] = result.UserID
+		updates["record_set_at"] = setAt
+	}
+
+	return tx.Model(&models.PromptStat{}).Where("prompt_id = ?", stat.PromptID).Updates(updates).Error
+}
+
+// TopResult is a player's best race on a prompt
+type TopResult struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	WPM       int       `json:"wpm"`
+	Accuracy  float64   `json:"accuracy"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Top returns the fastest players on a prompt, one entry per player
+func Top(db *gorm.DB, promptID uuid.UUID, limit int) ([]TopResult, error) {
+	best := db.Table("results").
+		Select("DISTINCT ON (results.user_id) results.user_id, users.username, results.wpm, results.accuracy, results.created_at").
+		Joins("JOIN users ON users.id = results.user_id").
+		Where("results.prompt_id = ?", promptID).
+		Order("results.user_id, results.wpm DESC, results.accuracy DESC, results.created_at ASC")
+
+	var top []TopResult
+	err := db.Table("(?) AS best", best).
+		Order("wpm DESC, accuracy DESC, created_at ASC").
+		Limit(limit).
+		Scan(&top).Error
+	return top, err
+}
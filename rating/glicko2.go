@@ -0,0 +1,119 @@
+package rating
+
+import (
+	"math"
+)
+
+// Glicko-2 as in Glickman's example, ratings are stored on the Glicko scale
+const (
+	DefaultRating     = 1500.0
+	DefaultRD         = 350.0
+	DefaultVolatility = 0.06
+
+	// tau constrains how much volatility can change between races
+	tau = 0.5
+
+	scale   = 173.7178
+	epsilon = 0.000001
+)
+
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	RD         float64 `json:"rd"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Outcome is a game against an opponent, Score is 1, 0.5 or 0
+type Outcome struct {
+	Opponent Rating
+	Score    float64
+}
+
+func Default() Rating {
+	return Rating{Rating: DefaultRating, RD: DefaultRD, Volatility: DefaultVolatility}
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, phiJ float64) float64 {
+	return 1 / (1 + math.Exp(-g(phiJ)*(mu-muJ)))
+}
+
+// Update returns the player's rating after a period, with no outcomes only RD grows
+func Update(player Rating, outcomes []Outcome) Rating {
+	mu := (player.Rating - DefaultRating) / scale
+	phi := player.RD / scale
+	sigma := player.Volatility
+
+	if len(outcomes) == 0 {
+		phiStar := math.Sqrt(phi*phi + sigma*sigma)
+		return Rating{
+			Rating:     player.Rating,
+			RD:         math.Min(phiStar*scale, DefaultRD),
+			Volatility: sigma,
+		}
+	}
+
+	var vInv, deltaSum float64
+	for _, o := range outcomes {
+		muJ := (o.Opponent.Rating - DefaultRating) / scale
+		phiJ := o.Opponent.RD / scale
+		gJ := g(phiJ)
+		e := expected(mu, muJ, phiJ)
+		vInv += gJ * gJ * e * (1 - e)
+		deltaSum += gJ * (o.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * deltaSum
+
+	sigmaPrime := newVolatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigmaPrime*sigmaPrime)
+	phiPrime := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	muPrime := mu + phiPrime*phiPrime*deltaSum
+
+	return Rating{
+		Rating:     muPrime*scale + DefaultRating,
+		RD:         math.Min(phiPrime*scale, DefaultRD),
+		Volatility: sigmaPrime,
+	}
+}
+
+// newVolatility solves for the new volatility with the Illinois algorithm
+func newVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		num := ex * (delta*delta - phi*phi - v - ex)
+		den := 2 * math.Pow(phi*phi+v+ex, 2)
+		return num/den - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,68 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		player   Rating
+		outcomes []Outcome
+		want     Rating
+	}{
+		{
+			// The worked example from Glickman's paper
+			name:   "paper example",
+			player: Rating{Rating: 1500, RD: 200, Volatility: 0.06},
+			outcomes: []Outcome{
+				{Opponent: Rating{Rating: 1400, RD: 30, Volatility: 0.06}, Score: 1},
+				{Opponent: Rating{Rating: 1550, RD: 100, Volatility: 0.06}, Score: 0},
+				{Opponent: Rating{Rating: 1700, RD: 300, Volatility: 0.06}, Score: 0},
+			},
+			want: Rating{Rating: 1464.06, RD: 151.52, Volatility: 0.05999},
+		},
+		{
+			name:   "no games only grows the deviation",
+			player: Rating{Rating: 1500, RD: 200, Volatility: 0.06},
+			want:   Rating{Rating: 1500, RD: 200.27, Volatility: 0.06},
+		},
+		{
+			name:   "deviation never grows past the default",
+			player: Default(),
+			want:   Default(),
+		},
+		{
+			name:     "new player beating a new player",
+			player:   Default(),
+			outcomes: []Outcome{{Opponent: Default(), Score: 1}},
+			want:     Rating{Rating: 1662.31, RD: 290.32, Volatility: 0.06},
+		},
+		{
+			name:     "new player losing to a new player",
+			player:   Default(),
+			outcomes: []Outcome{{Opponent: Default(), Score: 0}},
+			want:     Rating{Rating: 1337.69, RD: 290.32, Volatility: 0.06},
+		},
+		{
+			name:     "draw between equals keeps the rating",
+			player:   Default(),
+			outcomes: []Outcome{{Opponent: Default(), Score: 0.5}},
+			want:     Rating{Rating: 1500, RD: 290.32, Volatility: 0.06},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Update(tt.player, tt.outcomes)
+			if !near(got.Rating, tt.want.Rating, 0.01) || !near(got.RD, tt.want.RD, 0.01) || !near(got.Volatility, tt.want.Volatility, 0.00001) {
+				t.Errorf("Update() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
@@ -0,0 +1,91 @@
+package rating
+
+import (
+	"fmt"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ForUser returns the user's stored rating, or the default one
+func ForUser(db *gorm.DB, userID uuid.UUID) (models.UserRating, error) {
+	var r models.UserRating
+	err := db.Where("user_id = ?", userID).First(&r).Error
+	if err == gorm.ErrRecordNotFound {
+		d := Default()
+		return models.UserRating{UserID: userID, Rating: d.Rating, RD: d.RD, Volatility: d.Volatility}, nil
+	}
+	return r, err
+}
+
+// ApplyResults updates both players' ratings, in the tx that saved the results
+func ApplyResults(tx *gorm.DB, roomCode string, results []models.Results) error {
+	if len(results) != 2 {
+		return fmt.Errorf("ranked race needs exactly 2 results, got %d", len(results))
+	}
+
+	// Lock both rows so concurrent races of the same player apply in order
+	current := make(map[uuid.UUID]models.UserRating, 2)
+	for _, res := range results {
+		var r models.UserRating
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", res.UserID).
+			First(&r).Error
+		if err == gorm.ErrRecordNotFound {
+			r, err = ForUser(tx, res.UserID)
+		}
+		if err != nil {
+			return err
+		}
+		current[res.UserID] = r
+	}
+
+	// Compute both updates from the pre-race ratings before saving either
+	updated := make(map[uuid.UUID]Rating, 2)
+	for _, res := range results {
+		score := 0.0
+		if res.Won {
+			score = 1
+		}
+		updated[res.UserID] = Update(toRating(current[res.UserID]), []Outcome{
+			{Opponent: toRating(current[res.OpponentID]), Score: score},
+		})
+	}
+
+	for _, res := range results {
+		before := current[res.UserID]
+		after := updated[res.UserID]
+
+		stored := models.UserRating{
+			UserID:     res.UserID,
+			Rating:     after.Rating,
+			RD:         after.RD,
+			Volatility: after.Volatility,
+			RatedRaces: before.RatedRaces + 1,
+		}
+		if err := tx.Save(&stored).Error; err != nil {
+			return err
+		}
+
+		history := models.RatingHistory{
+			UserID:       res.UserID,
+			ResultID:     res.ID,
+			RoomCode:     roomCode,
+			RatingBefore: before.Rating,
+			RatingAfter:  after.Rating,
+			RDBefore:     before.RD,
+			RDAfter:      after.RD,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toRating(r models.UserRating) Rating {
+	return Rating{Rating: r.Rating, RD: r.RD, Volatility: r.Volatility}
+}
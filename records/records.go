@@ -0,0 +1,199 @@
+package records
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DurationKey names the bucket a race of the given mode and length falls in
+func DurationKey(mode models.PromptMode, duration int) string {
+	if mode == "" {
+		mode = models.PromptModeText
+	}
+	return fmt.Sprintf("%s:%d", mode, duration)
+}
+
+// candidates lists the personal bests a result competes for, custom texts compete for none
+func candidates(room models.Room, result models.Results) []models.PersonalBest {
+	var bests []models.PersonalBest
+	if room.ID != uuid.Nil && !room.Custom {
+		duration := room.Duration
+		if duration == 0 {
+			duration = models.DefaultRaceDuration
+		}
+		mode := room.Mode
+		if mode == "" {
+			mode = models.PromptModeText
+		}
+		bests = append(bests, models.PersonalBest{
+			Scope:    models.RecordScopeDuration,
+			Key:      DurationKey(mode, duration),
+			Mode:     mode,
+			Duration: duration,
+		})
+	}
+	if result.PromptID != nil {
+		mode := room.Mode
+		if mode == "" {
+			mode = models.PromptModeText
+		}
+		bests = append(bests, models.PersonalBest{
+			Scope:    models.RecordScopePrompt,
+			Key:      result.PromptID.String(),
+			Mode:     mode,
+			PromptID: result.PromptID,
+		})
+	}
+	return bests
+}
+
+// RecordResult returns the personal bests a result set, in the tx that created it
+func RecordResult(tx *gorm.DB, room models.Room, result models.Results) ([]models.PersonalBest, error) {
+	setAt := result.CreatedAt
+	if setAt.IsZero() {
+		setAt = time.Now()
+	}
+
+	var set []models.PersonalBest
+	for _, best := range candidates(room, result) {
+		var current models.PersonalBest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND scope = ? AND key = ?", result.UserID, best.Scope, best.Key).
+			Limit(1).
+			Find(&current).Error
+		if err != nil {
+			return nil, err
+		}
+		if current.ID != uuid.Nil && result.WPM <= current.WPM {
+			continue
+		}
+
+		best.UserID = result.UserID
+		best.ResultID = &result.ID
+		best.WPM = result.WPM
+		best.RawWPM = result.RawWPM
+		best.Accuracy = result.Accuracy
+		best.SetAt = setAt
+
+		if current.ID == uuid.Nil {
+			// Another race of the same user may have set it meanwhile
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&best)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+		} else {
+			best.ID = current.ID
+			best.PreviousWPM = &current.WPM
+			err := tx.Model(&current).Updates(map[string]interface{}{
+				"result_id":    best.ResultID,
+				"wpm":          best.WPM,
+				"raw_wpm":      best.RawWPM,
+				"accuracy":     best.Accuracy,
+				"previous_wpm": current.WPM,
+				"set_at":       best.SetAt,
+			}).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+		set = append(set, best)
+	}
+	return set, nil
+}
+
+// day truncates t to its UTC day
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// fold counts a race won or lost at the given time into the streaks
+func fold(streak *models.UserStreak, won bool, at time.Time) {
+	today := day(at)
+
+	if won {
+		streak.WinStreak++
+	} else {
+		streak.WinStreak = 0
+	}
+	streak.BestWinStreak = max(streak.BestWinStreak, streak.WinStreak)
+
+	switch {
+	case streak.LastActiveDay == nil:
+		streak.DailyStreak = 1
+	case day(*streak.LastActiveDay).Equal(today):
+		// Already raced today
+	case day(*streak.LastActiveDay).Equal(today.AddDate(0, 0, -1)):
+		streak.DailyStreak++
+	case day(*streak.LastActiveDay).Before(today):
+		streak.DailyStreak = 1
+	}
+	if streak.LastActiveDay == nil || day(*streak.LastActiveDay).Before(today) {
+		streak.LastActiveDay = &today
+	}
+	streak.BestDailyStreak = max(streak.BestDailyStreak, streak.DailyStreak)
+}
+
+// UpdateStreaks folds a result into the user's win and daily streaks
+func UpdateStreaks(tx *gorm.DB, result models.Results) error {
+	at := result.CreatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	// Create the row first so concurrent races of a new user both lock it
+	// instead of each inserting a fresh streak
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoNothing: true,
+	}).Create(&models.UserStreak{UserID: result.UserID}).Error
+	if err != nil {
+		return err
+	}
+
+	var streak models.UserStreak
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", result.UserID).
+		First(&streak).Error
+	if err != nil {
+		return err
+	}
+	fold(&streak, result.Won, at)
+
+	return tx.Select("win_streak", "best_win_streak", "daily_streak", "best_daily_streak", "last_active_day").
+		Save(&streak).Error
+}
+
+// Streak returns the user's streaks as of now, lapsing the daily one after a missed day
+func Streak(db *gorm.DB, userID uuid.UUID, now time.Time) (models.UserStreak, error) {
+	var streak models.UserStreak
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&streak).Error; err != nil {
+		return streak, err
+	}
+	streak.UserID = userID
+	if streak.LastActiveDay != nil && day(*streak.LastActiveDay).Before(day(now).AddDate(0, 0, -1)) {
+		streak.DailyStreak = 0
+	}
+	return streak, nil
+}
+
+// List returns the user's personal bests in scope, fastest first within each mode
+func List(db *gorm.DB, userID uuid.UUID, scope string) ([]models.PersonalBest, error) {
+	var bests []models.PersonalBest
+	query := db.Where("user_id = ? AND scope = ?", userID, scope)
+	if scope == models.RecordScopePrompt {
+		query = query.Preload("Prompt").Order("wpm DESC, set_at ASC")
+	} else {
+		query = query.Order("mode ASC, duration ASC")
+	}
+	err := query.Find(&bests).Error
+	return bests, err
+}
@@ -0,0 +1,79 @@
+package records
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+)
+
+func TestFold(t *testing.T) {
+	at := time.Date(2024, 3, 10, 23, 30, 0, 0, time.UTC)
+	daysAgo := func(n int) *time.Time {
+		d := day(at).AddDate(0, 0, -n)
+		return &d
+	}
+
+	tests := []struct {
+		name  string
+		start models.UserStreak
+		won   bool
+		want  models.UserStreak
+	}{
+		{
+			name:  "first race",
+			start: models.UserStreak{},
+			won:   true,
+			want:  models.UserStreak{WinStreak: 1, BestWinStreak: 1, DailyStreak: 1, BestDailyStreak: 1, LastActiveDay: daysAgo(0)},
+		},
+		{
+			name:  "again the same day",
+			start: models.UserStreak{WinStreak: 2, BestWinStreak: 2, DailyStreak: 3, BestDailyStreak: 3, LastActiveDay: daysAgo(0)},
+			won:   true,
+			want:  models.UserStreak{WinStreak: 3, BestWinStreak: 3, DailyStreak: 3, BestDailyStreak: 3, LastActiveDay: daysAgo(0)},
+		},
+		{
+			name:  "the next day",
+			start: models.UserStreak{DailyStreak: 3, BestDailyStreak: 3, LastActiveDay: daysAgo(1)},
+			won:   false,
+			want:  models.UserStreak{DailyStreak: 4, BestDailyStreak: 4, LastActiveDay: daysAgo(0)},
+		},
+		{
+			name:  "after a missed day",
+			start: models.UserStreak{DailyStreak: 5, BestDailyStreak: 5, LastActiveDay: daysAgo(2)},
+			won:   false,
+			want:  models.UserStreak{DailyStreak: 1, BestDailyStreak: 5, LastActiveDay: daysAgo(0)},
+		},
+		{
+			name:  "a loss ends the win streak",
+			start: models.UserStreak{WinStreak: 4, BestWinStreak: 6, DailyStreak: 1, BestDailyStreak: 1, LastActiveDay: daysAgo(0)},
+			won:   false,
+			want:  models.UserStreak{WinStreak: 0, BestWinStreak: 6, DailyStreak: 1, BestDailyStreak: 1, LastActiveDay: daysAgo(0)},
+		},
+		{
+			name:  "a result from an earlier day",
+			start: models.UserStreak{DailyStreak: 2, BestDailyStreak: 2, LastActiveDay: daysAgo(-1)},
+			won:   true,
+			want:  models.UserStreak{WinStreak: 1, BestWinStreak: 1, DailyStreak: 2, BestDailyStreak: 2, LastActiveDay: daysAgo(-1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.start
+			fold(&got, tt.won, at)
+
+			if got.WinStreak != tt.want.WinStreak || got.BestWinStreak != tt.want.BestWinStreak {
+				t.Errorf("win streak = %d (best %d), want %d (best %d)",
+					got.WinStreak, got.BestWinStreak, tt.want.WinStreak, tt.want.BestWinStreak)
+			}
+			if got.DailyStreak != tt.want.DailyStreak || got.BestDailyStreak != tt.want.BestDailyStreak {
+				t.Errorf("daily streak = %d (best %d), want %d (best %d)",
+					got.DailyStreak, got.BestDailyStreak, tt.want.DailyStreak, tt.want.BestDailyStreak)
+			}
+			if got.LastActiveDay == nil || !got.LastActiveDay.Equal(*tt.want.LastActiveDay) {
+				t.Errorf("last active day = %v, want %v", got.LastActiveDay, *tt.want.LastActiveDay)
+			}
+		})
+	}
+}
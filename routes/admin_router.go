@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Nitesh-04/realtime-racing/controllers"
+	"github.com/Nitesh-04/realtime-racing/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminRouter(api fiber.Router) {
+	admin := api.Group("/admin", middleware.RequireAdmin())
+
+	admin.Post("/seasons", controllers.CreateSeason)
+	admin.Post("/seasons/:id/archive", controllers.ArchiveSeason)
+
+	admin.Get("/prompts", controllers.ListPrompts)
+	admin.Post("/prompts", controllers.CreatePrompt)
+	admin.Post("/prompts/import", controllers.ImportPrompts)
+	admin.Get("/prompts/export", controllers.ExportPrompts)
+	admin.Get("/prompts/:id", controllers.GetPromptAdmin)
+	admin.Put("/prompts/:id", controllers.UpdatePrompt)
+	admin.Delete("/prompts/:id", controllers.RetirePrompt)
+	admin.Post("/prompts/:id/approve", controllers.ApprovePrompt)
+	admin.Post("/prompts/:id/reject", controllers.RejectPrompt)
+}
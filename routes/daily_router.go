@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Nitesh-04/realtime-racing/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func DailyRouter(api fiber.Router) {
+	api.Get("/daily", controllers.GetDaily)
+	api.Get("/daily/history", controllers.ListDailyChallenges)
+	api.Post("/daily/attempts", controllers.StartDailyAttempt)
+	api.Post("/daily/attempts/:id/finish", controllers.FinishDailyAttempt)
+	api.Get("/daily/:date", controllers.GetDailyByDate)
+}
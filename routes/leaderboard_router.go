@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/Nitesh-04/realtime-racing/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func LeaderboardRouter(api fiber.Router) {
+	api.Get("/leaderboard", controllers.GetLeaderboard)
+}
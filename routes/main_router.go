@@ -11,4 +11,10 @@ func MainRouter(api fiber.Router) {
 	AuthRouter(api)
 	RaceRouter(api)
 	UserRouter(api)
+	MatchmakingRouter(api)
+	LeaderboardRouter(api)
+	SeasonRouter(api)
+	PromptRouter(api)
+	DailyRouter(api)
+	AdminRouter(api)
 }
\ No newline at end of file
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/Nitesh-04/realtime-racing/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func MatchmakingRouter(api fiber.Router) {
+	api.Post("/matchmaking/join", controllers.JoinMatchmaking)
+	api.Post("/matchmaking/leave", controllers.LeaveMatchmaking)
+	api.Get("/matchmaking/status", controllers.MatchmakingStatus)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Nitesh-04/realtime-racing/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func PromptRouter(api fiber.Router) {
+	api.Get("/prompts", controllers.ListActivePrompts)
+	api.Post("/prompts", controllers.SubmitPrompt)
+	api.Get("/prompts/languages", controllers.ListPromptLanguages)
+	api.Get("/prompts/mine", controllers.ListMyPrompts)
+	api.Get("/prompts/:id", controllers.GetPrompt)
+}
@@ -9,6 +9,8 @@ func RaceRouter(api fiber.Router) {
 	api.Post("/race/create", controllers.CreateRoom)
 	api.Post("/race/join/:roomCode", controllers.JoinRoom)
 	api.Post("/race/leave/:roomCode", controllers.LeaveRoom)
+	api.Post("/race/practice", controllers.CreatePracticeRoom)
+	api.Post("/race/bot/:roomCode", controllers.AddBot)
 	api.Get("/race/:roomCode", controllers.GetRoomDetails)
 	api.Post("/race/over/:roomCode", controllers.GameOver)
 	api.Post("/race/updateResults/:roomCode", controllers.UpdateUserResult)
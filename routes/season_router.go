@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/Nitesh-04/realtime-racing/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SeasonRouter(api fiber.Router) {
+	api.Get("/seasons", controllers.ListSeasons)
+	api.Get("/seasons/:id/leaderboard", controllers.GetSeasonLeaderboard)
+}
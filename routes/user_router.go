@@ -7,5 +7,12 @@ import (
 
 func UserRouter(api fiber.Router) {
 	api.Get("/user/results", controllers.GetUserResults)
+	api.Get("/user/results/export", controllers.ExportUserResults)
 	api.Get("/user/stats", controllers.GetUserStats)
+	api.Get("/user/stats/history", controllers.GetUserStatsHistory)
+	api.Get("/user/keys", controllers.GetUserKeys)
+	api.Get("/user/practice", controllers.GetPracticeProgress)
+	api.Get("/user/records", controllers.GetUserRecords)
+	api.Get("/user/vs/:username", controllers.GetUserVersus)
+	api.Get("/user/rivals", controllers.GetUserRivals)
 }
\ No newline at end of file
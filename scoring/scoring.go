@@ -0,0 +1,264 @@
+package scoring
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// CharsPerWord is the standard word length used for WPM
+const CharsPerWord = 5
+
+// WordRule is how a language turns typed grapheme clusters into words for WPM
+type WordRule struct {
+	GraphemesPerWord int  `json:"graphemes_per_word"`
+	CountSpaces      bool `json:"count_spaces"`
+}
+
+// DefaultRule is the usual 5 characters per word, spaces included
+var DefaultRule = WordRule{GraphemesPerWord: CharsPerWord, CountSpaces: true}
+
+// wordRules holds the base languages that don't follow DefaultRule
+var wordRules = map[string]WordRule{
+	"zh": {GraphemesPerWord: 5, CountSpaces: false},
+	"ja": {GraphemesPerWord: 5, CountSpaces: false},
+	"ko": {GraphemesPerWord: 5, CountSpaces: false},
+}
+
+// perWord is the word size WPM divides by, CharsPerWord when unset
+func (rule WordRule) perWord() int {
+	if rule.GraphemesPerWord <= 0 {
+		return CharsPerWord
+	}
+	return rule.GraphemesPerWord
+}
+
+// counted is how many of the clusters count towards WPM under the rule
+func (rule WordRule) counted(clusters []string) int {
+	if rule.CountSpaces {
+		return len(clusters)
+	}
+	n := 0
+	for _, ch := range clusters {
+		if strings.TrimSpace(ch) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// RuleFor returns the word rule for a language tag such as "en" or "zh-Hant"
+func RuleFor(language string) WordRule {
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	if rule, ok := wordRules[base]; ok {
+		return rule
+	}
+	return DefaultRule
+}
+
+// Result is the server-side evaluation of what a player typed so far
+type Result struct {
+	// WPM is RawWPM less a word per minute for each error left, never below 0
+	WPM      int     `json:"wpm"`
+	RawWPM   int     `json:"raw_wpm"`
+	Accuracy float64 `json:"accuracy"`
+
+	// Errors are still in the text, CorrectedErrors were fixed along the way
+	Errors          int `json:"errors"`
+	CorrectedErrors int `json:"corrected_errors"`
+
+	// Consistency is 100 for a perfectly even per-second raw WPM
+	Consistency float64 `json:"consistency"`
+
+	// Correct and Typed count grapheme clusters, Progress is 0-100 of the prompt
+	Correct  int     `json:"correct"`
+	Typed    int     `json:"typed"`
+	Progress float64 `json:"progress"`
+}
+
+// Graphemes splits NFC normalized text into grapheme clusters
+func Graphemes(text string) []string {
+	var clusters []string
+	g := uniseg.NewGraphemes(norm.NFC.String(text))
+	for g.Next() {
+		clusters = append(clusters, g.Str())
+	}
+	return clusters
+}
+
+// Length is the number of grapheme clusters in the text
+func Length(text string) int {
+	return uniseg.GraphemeClusterCount(norm.NFC.String(text))
+}
+
+// Score compares the typed text against the prompt one grapheme cluster at a time
+func Score(prompt, typed string, elapsed time.Duration, rule WordRule) Result {
+	r, _ := score(Graphemes(prompt), Graphemes(typed), elapsed, rule)
+	return r
+}
+
+// score returns the result and which typed positions are wrong
+func score(expected, actual []string, elapsed time.Duration, rule WordRule) (Result, []bool) {
+	var r Result
+	r.Typed = len(actual)
+
+	wrong := make([]bool, len(actual))
+	for i, ch := range actual {
+		if i < len(expected) && ch == expected[i] {
+			r.Correct++
+		} else {
+			r.Errors++
+			wrong[i] = true
+		}
+	}
+
+	if r.Typed > 0 {
+		r.Accuracy = round2(float64(r.Correct) / float64(r.Typed) * 100)
+	}
+	if len(expected) > 0 {
+		r.Progress = round2(math.Min(float64(r.Typed)/float64(len(expected)), 1) * 100)
+	}
+
+	if minutes := elapsed.Minutes(); minutes > 0 {
+		raw := float64(rule.counted(actual)) / float64(rule.perWord()) / minutes
+		r.RawWPM = int(math.Round(raw))
+		r.WPM = int(math.Round(math.Max(0, raw-float64(r.Errors)/minutes)))
+	}
+
+	return r, wrong
+}
+
+// Consistency is 100 minus the coefficient of variation of the samples in percent
+func Consistency(samples []float64) float64 {
+	if len(samples) < 2 {
+		return 100
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	mean := sum / float64(len(samples))
+	if mean == 0 {
+		return 0
+	}
+	var variance float64
+	for _, s := range samples {
+		variance += (s - mean) * (s - mean)
+	}
+	variance /= float64(len(samples))
+	cv := math.Sqrt(variance) / mean
+	return round2(math.Max(0, 100*(1-cv)))
+}
+
+// Tracker follows a player's snapshots to count corrections and measure consistency
+type Tracker struct {
+	prompt []string
+	rule   WordRule
+
+	wrong     map[int]bool // Positions that have been wrong at some point
+	corrected int
+
+	// Characters counting towards WPM typed within each second of the race,
+	// and the count and time of the last snapshot
+	perSecond []float64
+	lastCount int
+	lastAt    time.Duration
+}
+
+func NewTracker(prompt string, rule WordRule) *Tracker {
+	return &Tracker{prompt: Graphemes(prompt), rule: rule, wrong: map[int]bool{}}
+}
+
+// Update scores a new snapshot of the typed text at the given time
+func (t *Tracker) Update(typed string, elapsed time.Duration) Result {
+	actual := Graphemes(typed)
+	r, wrong := score(t.prompt, actual, elapsed, t.rule)
+
+	for i, w := range wrong {
+		if w {
+			t.wrong[i] = true
+		} else if t.wrong[i] {
+			// Was wrong before and has been fixed
+			t.corrected++
+			delete(t.wrong, i)
+		}
+	}
+	r.CorrectedErrors = t.corrected
+
+	// Snapshots don't arrive every second, what was typed since the last one
+	// is spread evenly over the time in between. Deletions don't undo typing
+	count := t.rule.counted(actual)
+	if delta := count - t.lastCount; delta > 0 {
+		t.spread(float64(delta), t.lastAt, elapsed)
+	}
+	t.lastCount = count
+	if elapsed > t.lastAt {
+		t.lastAt = elapsed
+	}
+
+	// Only whole seconds are sampled, the current one is still filling up
+	seconds := int(elapsed / time.Second)
+	if seconds > 0 {
+		t.addTo(seconds-1, 0)
+	}
+	samples := make([]float64, 0, seconds)
+	for _, n := range t.perSecond[:seconds] {
+		samples = append(samples, n*60/float64(t.rule.perWord()))
+	}
+	r.Consistency = Consistency(samples)
+
+	return r
+}
+
+// spread adds n characters typed between from and to to the seconds they fall in
+func (t *Tracker) spread(n float64, from, to time.Duration) {
+	if to <= from {
+		t.addTo(int(to/time.Second), n)
+		return
+	}
+	span := float64(to - from)
+	for start := from; start < to; {
+		second := int(start / time.Second)
+		end := min(time.Duration(second+1)*time.Second, to)
+		t.addTo(second, n*float64(end-start)/span)
+		start = end
+	}
+}
+
+func (t *Tracker) addTo(second int, n float64) {
+	for len(t.perSecond) <= second {
+		t.perSecond = append(t.perSecond, 0)
+	}
+	t.perSecond[second] += n
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ScoreCode scores a code race, ignoring the indentation at the start of each line
+func ScoreCode(prompt, typed string, elapsed time.Duration) Result {
+	return Score(StripIndent(prompt), StripIndent(typed), elapsed, DefaultRule)
+}
+
+// NewCodeTracker is NewTracker for code races, snapshots must go through StripIndent
+func NewCodeTracker(prompt string) *Tracker {
+	return NewTracker(StripIndent(prompt), DefaultRule)
+}
+
+// StripIndent drops leading and trailing whitespace on each line after the first
+func StripIndent(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := range lines {
+		if i > 0 {
+			lines[i] = strings.TrimLeft(lines[i], " \t")
+		}
+		if i < len(lines)-1 {
+			lines[i] = strings.TrimRight(lines[i], " \t")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
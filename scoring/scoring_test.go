@@ -0,0 +1,267 @@
+package scoring
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	cjk := RuleFor("zh")
+
+	tests := []struct {
+		name    string
+		prompt  string
+		typed   string
+		elapsed time.Duration
+		rule    WordRule
+		want    Result
+	}{
+		{
+			name:    "perfect",
+			prompt:  "hello world",
+			typed:   "hello world",
+			elapsed: 12 * time.Second,
+			rule:    DefaultRule,
+			want:    Result{WPM: 11, RawWPM: 11, Accuracy: 100, Correct: 11, Typed: 11, Progress: 100},
+		},
+		{
+			name:    "uncorrected error costs a word per minute",
+			prompt:  "hello world",
+			typed:   "hello wxrld",
+			elapsed: 12 * time.Second,
+			rule:    DefaultRule,
+			want:    Result{WPM: 6, RawWPM: 11, Accuracy: 90.91, Errors: 1, Correct: 10, Typed: 11, Progress: 100},
+		},
+		{
+			name:    "case matters",
+			prompt:  "Hello",
+			typed:   "hello",
+			elapsed: 12 * time.Second,
+			rule:    DefaultRule,
+			want:    Result{RawWPM: 5, Accuracy: 80, Errors: 1, Correct: 4, Typed: 5, Progress: 100},
+		},
+		{
+			name:    "partial",
+			prompt:  "hello world",
+			typed:   "hello",
+			elapsed: 12 * time.Second,
+			rule:    DefaultRule,
+			want:    Result{WPM: 5, RawWPM: 5, Accuracy: 100, Correct: 5, Typed: 5, Progress: 45.45},
+		},
+		{
+			name:    "typing past the end is an error",
+			prompt:  "hello",
+			typed:   "hello!",
+			elapsed: 12 * time.Second,
+			rule:    DefaultRule,
+			want:    Result{WPM: 1, RawWPM: 6, Accuracy: 83.33, Errors: 1, Correct: 5, Typed: 6, Progress: 100},
+		},
+		{
+			name:    "decomposed accent is one character",
+			prompt:  "caf\u00e9",
+			typed:   "cafe\u0301",
+			elapsed: 12 * time.Second,
+			rule:    DefaultRule,
+			want:    Result{WPM: 4, RawWPM: 4, Accuracy: 100, Correct: 4, Typed: 4, Progress: 100},
+		},
+		{
+			name:    "spaces don't count towards CJK words",
+			prompt:  "你好 世界",
+			typed:   "你好 世界",
+			elapsed: 12 * time.Second,
+			rule:    cjk,
+			want:    Result{WPM: 4, RawWPM: 4, Accuracy: 100, Correct: 5, Typed: 5, Progress: 100},
+		},
+		{
+			name:    "no time has passed",
+			prompt:  "hello",
+			typed:   "he",
+			elapsed: 0,
+			rule:    DefaultRule,
+			want:    Result{Accuracy: 100, Correct: 2, Typed: 2, Progress: 40},
+		},
+		{
+			name:    "nothing typed",
+			prompt:  "hello",
+			typed:   "",
+			elapsed: time.Second,
+			rule:    DefaultRule,
+			want:    Result{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(tt.prompt, tt.typed, tt.elapsed, tt.rule); got != tt.want {
+				t.Errorf("Score() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		want    float64
+	}{
+		{"no samples", nil, 100},
+		{"one sample", []float64{60}, 100},
+		{"even", []float64{60, 60, 60}, 100},
+		{"all idle", []float64{0, 0}, 0},
+		{"burst then idle", []float64{120, 0}, 0},
+		{"uneven", []float64{40, 60, 80}, 72.78},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Consistency(tt.samples); got != tt.want {
+				t.Errorf("Consistency(%v) = %v, want %v", tt.samples, got, tt.want)
+			}
+		})
+	}
+}
+
+type snapshot struct {
+	typed string
+	at    time.Duration
+}
+
+func TestTrackerPerSecond(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      WordRule
+		snapshots []snapshot
+		want      []float64
+	}{
+		{
+			name:      "sparse snapshots are spread over the seconds in between",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaaaaaaaaaaaaaaaaaaa", 4 * time.Second}},
+			want:      []float64{5, 5, 5, 5},
+		},
+		{
+			name:      "a snapshot mid-second splits across both seconds",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaa", 1500 * time.Millisecond}},
+			want:      []float64{2, 1},
+		},
+		{
+			name:      "idle seconds stay empty",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaaaa", time.Second}, {"aaaaa", 3 * time.Second}},
+			want:      []float64{5, 0, 0},
+		},
+		{
+			name:      "deleting doesn't undo typing",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaa", time.Second}, {"a", 2 * time.Second}, {"aaa", 3 * time.Second}},
+			want:      []float64{3, 0, 2},
+		},
+		{
+			name:      "spaces follow the word rule",
+			rule:      WordRule{GraphemesPerWord: 5, CountSpaces: false},
+			snapshots: []snapshot{{"aa aa aa aa", 2 * time.Second}},
+			want:      []float64{4, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker("aa aa aa aa aaaaaaaaaaaaaaaaaaaaaaaaaa", tt.rule)
+			for _, s := range tt.snapshots {
+				tracker.Update(s.typed, s.at)
+			}
+			if !slices.Equal(tracker.perSecond, tt.want) {
+				t.Errorf("perSecond = %v, want %v", tracker.perSecond, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerConsistency(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      WordRule
+		snapshots []snapshot
+		want      float64
+	}{
+		{
+			name:      "one late snapshot of even typing",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaaaaaaaaaaaaaaaaaaa", 4 * time.Second}},
+			want:      100,
+		},
+		{
+			name:      "burst then idle",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaaaaaaaaa", time.Second}, {"aaaaaaaaaa", 2 * time.Second}},
+			want:      0,
+		},
+		{
+			name:      "current second isn't sampled yet",
+			rule:      DefaultRule,
+			snapshots: []snapshot{{"aaaaa", time.Second}, {"aaaaaaaaaaaaaaaaaaaa", 1500 * time.Millisecond}},
+			want:      100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", tt.rule)
+			var r Result
+			for _, s := range tt.snapshots {
+				r = tracker.Update(s.typed, s.at)
+			}
+			if r.Consistency != tt.want {
+				t.Errorf("Consistency = %v, want %v", r.Consistency, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerCorrections(t *testing.T) {
+	tracker := NewTracker("abc", DefaultRule)
+
+	steps := []struct {
+		typed     string
+		errors    int
+		corrected int
+	}{
+		{"ax", 1, 0},
+		{"a", 0, 0},
+		{"ab", 0, 1},
+		{"abx", 1, 1},
+		{"abc", 0, 2},
+	}
+
+	for i, s := range steps {
+		r := tracker.Update(s.typed, time.Duration(i+1)*time.Second)
+		if r.Errors != s.errors || r.CorrectedErrors != s.corrected {
+			t.Errorf("step %d %q: errors %d corrected %d, want %d and %d",
+				i, s.typed, r.Errors, r.CorrectedErrors, s.errors, s.corrected)
+		}
+	}
+}
+
+func TestStripIndent(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"single line", "  x := 1", "  x := 1"},
+		{"indentation after the first line", "if x {\n\treturn\n}", "if x {\nreturn\n}"},
+		{"trailing spaces", "a  \nb", "a\nb"},
+		{"windows line endings", "a\r\n  b", "a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripIndent(tt.in); got != tt.want {
+				t.Errorf("StripIndent(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
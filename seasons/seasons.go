@@ -0,0 +1,170 @@
+package seasons
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Standing struct {
+	Rank     int       `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Races    int       `json:"races"`
+	Wins     int       `json:"wins"`
+	AvgWPM   float64   `json:"avg_wpm"`
+	BestWPM  int       `json:"best_wpm"`
+}
+
+// Current returns the season running at t, or nil between seasons
+func Current(db *gorm.DB, t time.Time) (*models.Season, error) {
+	var season models.Season
+	err := db.Where("starts_at <= ? AND ends_at > ?", t, t).
+		Order("starts_at DESC").
+		First(&season).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// Overlaps reports whether another season intersects [start, end)
+func Overlaps(db *gorm.DB, start, end time.Time) (bool, error) {
+	var count int64
+	err := db.Model(&models.Season{}).
+		Where("starts_at < ? AND ends_at > ?", end, start).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// LiveStandings ranks players by wins then average WPM, a limit of 0 returns everyone
+func LiveStandings(db *gorm.DB, seasonID uuid.UUID, limit int) ([]Standing, error) {
+	query := db.Table("results").
+		Select("results.user_id, users.username, COUNT(*) AS races, "+
+			"SUM(CASE WHEN results.won THEN 1 ELSE 0 END) AS wins, "+
+			"AVG(results.wpm) AS avg_wpm, MAX(results.wpm) AS best_wpm").
+		Joins("JOIN users ON users.id = results.user_id").
+		Where("results.season_id = ?", seasonID).
+		Group("results.user_id, users.username").
+		Order("wins DESC, avg_wpm DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var standings []Standing
+	if err := query.Scan(&standings).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings, nil
+}
+
+// ArchivedStandings reads the snapshot taken when the season was archived
+func ArchivedStandings(db *gorm.DB, seasonID uuid.UUID, limit int) ([]Standing, error) {
+	query := db.Model(&models.SeasonStanding{}).
+		Where("season_id = ?", seasonID).
+		Order("rank ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rows []models.SeasonStanding
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	standings := make([]Standing, 0, len(rows))
+	for _, r := range rows {
+		standings = append(standings, Standing{
+			Rank:     r.Rank,
+			UserID:   r.UserID,
+			Username: r.Username,
+			Races:    r.Races,
+			Wins:     r.Wins,
+			AvgWPM:   r.AvgWPM,
+			BestWPM:  r.BestWPM,
+		})
+	}
+	return standings, nil
+}
+
+// Standings serves archived seasons from the snapshot and others live
+func Standings(db *gorm.DB, season models.Season, limit int) ([]Standing, error) {
+	if season.Archived {
+		return ArchivedStandings(db, season.ID, limit)
+	}
+	return LiveStandings(db, season.ID, limit)
+}
+
+// Archive snapshots the final standings of an ended season
+func Archive(db *gorm.DB, season *models.Season) error {
+	if season.Archived {
+		return fmt.Errorf("season is already archived")
+	}
+	if time.Now().Before(season.EndsAt) {
+		return fmt.Errorf("season has not ended yet")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		standings, err := LiveStandings(tx, season.ID, 0)
+		if err != nil {
+			return err
+		}
+
+		rows := make([]models.SeasonStanding, 0, len(standings))
+		for _, s := range standings {
+			rows = append(rows, models.SeasonStanding{
+				SeasonID: season.ID,
+				UserID:   s.UserID,
+				Username: s.Username,
+				Rank:     s.Rank,
+				Races:    s.Races,
+				Wins:     s.Wins,
+				AvgWPM:   s.AvgWPM,
+				BestWPM:  s.BestWPM,
+			})
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(&rows, 200).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		season.Archived = true
+		season.ArchivedAt = &now
+		return tx.Save(season).Error
+	})
+}
+
+// StartArchiver archives seasons as they end
+func StartArchiver(db *gorm.DB) {
+	ticker := time.NewTicker(time.Hour)
+	log.Printf("Starting season archiver every hour")
+	go func() {
+		for ; true; <-ticker.C {
+			var ended []models.Season
+			if err := db.Where("archived = false AND ends_at <= ?", time.Now()).Find(&ended).Error; err != nil {
+				log.Printf("Season archiver: %v", err)
+				continue
+			}
+			for i := range ended {
+				if err := Archive(db, &ended[i]); err != nil {
+					log.Printf("Failed to archive season %s: %v", ended[i].Name, err)
+				} else {
+					log.Printf("Archived season %s", ended[i].Name)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,221 @@
+package websockets
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/scoring"
+)
+
+// BotUsernamePrefix marks bot players in player_list and stats payloads
+const BotUsernamePrefix = "bot:"
+
+const botTickInterval = 500 * time.Millisecond
+
+// botCorrectionRate is the share of mistakes a bot goes back and fixes
+const botCorrectionRate = 0.8
+
+// botIdleTimeout removes bots that have been left alone in a room
+const botIdleTimeout = 2 * time.Minute
+
+// BotConfig describes how a bot types, Variability is the relative deviation per tick
+type BotConfig struct {
+	TargetWPM   int     `json:"wpm"`
+	Accuracy    float64 `json:"accuracy"`
+	Variability float64 `json:"variability"`
+}
+
+var BotSkills = map[string]BotConfig{
+	"easy":   {TargetWPM: 35, Accuracy: 90, Variability: 0.20},
+	"medium": {TargetWPM: 65, Accuracy: 95, Variability: 0.12},
+	"hard":   {TargetWPM: 100, Accuracy: 98, Variability: 0.08},
+}
+
+// IsBotUsername reports whether the username belongs to a server-side bot
+func IsBotUsername(username string) bool {
+	return strings.HasPrefix(username, BotUsernamePrefix)
+}
+
+// Validate checks that the bot config is within sane bounds
+func (b BotConfig) Validate() error {
+	if b.TargetWPM < 1 || b.TargetWPM > 250 {
+		return fmt.Errorf("wpm must be between 1 and 250")
+	}
+	if b.Accuracy < 50 || b.Accuracy > 100 {
+		return fmt.Errorf("accuracy must be between 50 and 100")
+	}
+	if b.Variability < 0 || b.Variability > 1 {
+		return fmt.Errorf("variability must be between 0 and 1")
+	}
+	return nil
+}
+
+// AddBot fills an empty slot in the room with a bot and returns its username
+func (h *GameHub) AddBot(roomCode string, bot BotConfig) (string, error) {
+	if err := bot.Validate(); err != nil {
+		return "", err
+	}
+
+	var room models.Room
+	if err := config.DB.Where("room_code = ?", roomCode).First(&room).Error; err != nil {
+		return "", fmt.Errorf("room not found")
+	}
+
+	if room.Practice {
+		return "", fmt.Errorf("practice rooms are solo")
+	}
+
+	h.mu.RLock()
+	playerCount := len(h.connections[roomCode])
+	stage := h.gameStates[roomCode].Stage
+	h.mu.RUnlock()
+
+	if playerCount >= 2 {
+		return "", fmt.Errorf("room is full")
+	}
+	if stage != "" && stage != "waiting" {
+		return "", fmt.Errorf("race has already started")
+	}
+
+	username := fmt.Sprintf("%s%d-%04d", BotUsernamePrefix, bot.TargetWPM, rand.Intn(10000))
+	conn := &Connection{RoomCode: roomCode, Username: username, IsBot: true}
+
+	h.mu.Lock()
+	h.prompts[roomCode] = racePromptFor(room)
+	h.mu.Unlock()
+
+	h.addConnection(conn)
+	go h.runBot(conn, bot, scoring.Length(room.Prompt))
+
+	log.Printf("Bot '%s' added to room '%s' (wpm=%d accuracy=%.1f variability=%.2f)",
+		username, roomCode, bot.TargetWPM, bot.Accuracy, bot.Variability)
+
+	return username, nil
+}
+
+// hasConnection reports whether the connection is still registered in its room
+func (h *GameHub) hasConnection(conn *Connection) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, c := range h.connections[conn.RoomCode] {
+		if c == conn {
+			return true
+		}
+	}
+	return false
+}
+
+// runBot types through the prompt once the race starts, like a real client
+func (h *GameHub) runBot(conn *Connection, bot BotConfig, promptLen int) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ticker := time.NewTicker(botTickInterval)
+	defer ticker.Stop()
+
+	target := float64(bot.TargetWPM)
+	speed := target
+	typed := 0.0
+	errors := 0.0  // Mistakes left in the text
+	corrected := 0 // Mistakes the bot went back and fixed
+	var speeds []float64
+	var started time.Time
+	aloneSince := time.Now()
+
+	for range ticker.C {
+		if !h.hasConnection(conn) {
+			log.Printf("Bot '%s' left room '%s'", conn.Username, conn.RoomCode)
+			return
+		}
+
+		h.mu.RLock()
+		state := h.gameStates[conn.RoomCode]
+		humans := 0
+		for _, c := range h.connections[conn.RoomCode] {
+			if !c.IsBot {
+				humans++
+			}
+		}
+		h.mu.RUnlock()
+
+		if humans > 0 {
+			aloneSince = time.Now()
+		} else if time.Since(aloneSince) > botIdleTimeout {
+			log.Printf("Bot '%s' idle in room '%s', removing", conn.Username, conn.RoomCode)
+			h.removeConnection(conn)
+			return
+		}
+
+		switch state.Stage {
+		case "racing":
+		case "finished":
+			return
+		default:
+			// Reset in case a previous countdown was cancelled
+			started = time.Time{}
+			typed, errors, corrected, speed = 0, 0, 0, target
+			speeds = nil
+			continue
+		}
+
+		if started.IsZero() {
+			started = state.StartTime
+		}
+
+		if promptLen > 0 && typed >= float64(promptLen) {
+			continue // Finished the prompt, keep the final stats
+		}
+
+		// Drift towards the target speed with some per-tick noise
+		sample := target * (1 + bot.Variability*r.NormFloat64())
+		speed = math.Max(0, 0.7*speed+0.3*sample)
+		speeds = append(speeds, speed)
+
+		chars := speed * 5 / 60 * botTickInterval.Seconds()
+		typed += chars
+		if promptLen > 0 && typed > float64(promptLen) {
+			typed = float64(promptLen)
+		}
+		for i := 0; i < int(math.Round(chars)); i++ {
+			if r.Float64()*100 > bot.Accuracy {
+				if r.Float64() < botCorrectionRate {
+					corrected++
+				} else {
+					errors++
+				}
+			}
+		}
+
+		elapsed := time.Since(started).Minutes()
+		if elapsed <= 0 || typed == 0 {
+			continue
+		}
+
+		rawWPM := typed / 5 / elapsed
+		mistakes := errors + float64(corrected)
+		stats := PlayerStats{
+			WPM:             int(math.Round(math.Max(0, rawWPM-errors/elapsed))),
+			RawWPM:          int(math.Round(rawWPM)),
+			Accuracy:        math.Max(0, math.Round((typed-mistakes)/typed*10000)/100),
+			Error:           errors,
+			CorrectedErrors: corrected,
+			Consistency:     scoring.Consistency(speeds),
+			Typed:           int(typed),
+		}
+		if promptLen > 0 {
+			stats.Progress = math.Round(typed/float64(promptLen)*10000) / 100
+		}
+
+		raw, err := json.Marshal(Message{Type: "stats_update", Payload: stats})
+		if err != nil {
+			log.Printf("Bot '%s' failed to encode stats: %v", conn.Username, err)
+			continue
+		}
+		h.handleMessage(conn, raw)
+	}
+}
@@ -0,0 +1,139 @@
+package websockets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/gofiber/websocket/v2"
+	"github.com/google/uuid"
+)
+
+// ticketTTL is how long a matchmade room waits for its players to connect
+const ticketTTL = 2 * time.Minute
+
+type roomTickets struct {
+	byUser   map[string]string
+	issuedAt time.Time
+}
+
+// LobbyHub keeps a socket per authenticated user outside of any room
+type LobbyHub struct {
+	connections map[uuid.UUID][]*Connection
+	mu          sync.RWMutex
+}
+
+var Lobby = &LobbyHub{
+	connections: make(map[uuid.UUID][]*Connection),
+}
+
+// HandleConnection serves a lobby socket authenticated by CheckSocketAuth
+func (l *LobbyHub) HandleConnection(c *websocket.Conn) {
+	userID, err := uuid.Parse(fmt.Sprint(c.Locals("userId")))
+
+	if err != nil {
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Unauthorized"))
+		c.Close()
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "username").First(&user, "id = ?", userID).Error; err != nil {
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "User not found"))
+		c.Close()
+		return
+	}
+
+	conn := &Connection{Conn: c, Username: user.Username}
+
+	l.mu.Lock()
+	l.connections[userID] = append(l.connections[userID], conn)
+	l.mu.Unlock()
+
+	log.Printf("Player '%s' connected to lobby", user.Username)
+
+	defer func() {
+		l.mu.Lock()
+		conns := l.connections[userID]
+		for i, existing := range conns {
+			if existing == conn {
+				l.connections[userID] = append(conns[:i], conns[i+1:]...)
+				break
+			}
+		}
+		if len(l.connections[userID]) == 0 {
+			delete(l.connections, userID)
+		}
+		l.mu.Unlock()
+		conn.Conn.Close()
+		log.Printf("Player '%s' disconnected from lobby", user.Username)
+	}()
+
+	// The lobby is push only, reads just keep the connection alive
+	for {
+		if _, _, err := c.ReadMessage(); err != nil {
+			break
+		}
+	}
+}
+
+// NotifyUser sends a message to every lobby socket of the user
+func (l *LobbyHub) NotifyUser(userID uuid.UUID, msgType string, payload interface{}) {
+	jsonMessage, err := json.Marshal(Message{Type: msgType, Payload: payload})
+	if err != nil {
+		log.Printf("Error marshaling lobby message: %v", err)
+		return
+	}
+
+	l.mu.RLock()
+	connections := make([]*Connection, len(l.connections[userID]))
+	copy(connections, l.connections[userID])
+	l.mu.RUnlock()
+
+	for _, conn := range connections {
+		if err := conn.SafeWriteMessage(websocket.TextMessage, jsonMessage); err != nil {
+			log.Printf("Error sending lobby message to %s: %v", conn.Username, err)
+		}
+	}
+}
+
+// IssueTicket reserves a slot in the room for the username
+func (h *GameHub) IssueTicket(roomCode, username string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	ticket := hex.EncodeToString(buf)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.tickets[roomCode]; !exists {
+		h.tickets[roomCode] = &roomTickets{
+			byUser:   make(map[string]string),
+			issuedAt: time.Now(),
+		}
+	}
+	h.tickets[roomCode].byUser[username] = ticket
+
+	return ticket, nil
+}
+
+// checkTicket reports whether the player may join, rooms without tickets are open
+func (h *GameHub) checkTicket(roomCode, username, ticket string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	t, exists := h.tickets[roomCode]
+	if !exists {
+		return true
+	}
+	expected, ok := t.byUser[username]
+	return ok && expected == ticket
+}
@@ -0,0 +1,169 @@
+package websockets
+
+import (
+	"log"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/achievements"
+	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/keystats"
+	"github.com/Nitesh-04/realtime-racing/leaderboard"
+	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/percentile"
+	"github.com/Nitesh-04/realtime-racing/prompts"
+	"github.com/Nitesh-04/realtime-racing/rating"
+	"github.com/Nitesh-04/realtime-racing/records"
+	"gorm.io/gorm"
+)
+
+// RaceRecords holds what each player set or unlocked in a race, keyed by username
+type RaceRecords struct {
+	PersonalBests map[string][]models.PersonalBest
+	Achievements  map[string][]achievements.Unlock
+	Percentiles   map[string]*percentile.Standing
+}
+
+// saveResults persists the winner, results, ratings and what each player earned
+func saveResults(room models.Room, stats map[string]PlayerStats, winnerUsername string) (*RaceRecords, error) {
+	// Bots are not users, so only real players are looked up
+	players := make(map[string]models.User, len(stats))
+	for username := range stats {
+		var user models.User
+		if err := config.DB.Where("username = ?", username).First(&user).Error; err == nil {
+			players[username] = user
+		}
+	}
+
+	winner, ok := players[winnerUsername]
+	if !ok {
+		return nil, nil
+	}
+
+	mode := room.Mode
+	if mode == "" {
+		mode = models.PromptModeText
+	}
+
+	earned := &RaceRecords{
+		PersonalBests: make(map[string][]models.PersonalBest),
+		Achievements:  make(map[string][]achievements.Unlock),
+		Percentiles:   make(map[string]*percentile.Standing),
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Room{}).
+			Where("id = ?", room.ID).
+			Update("winner_id", winner.ID).Error; err != nil {
+			return err
+		}
+
+		var saved []models.Results
+
+		for username, s := range stats {
+			user, ok := players[username]
+			if !ok {
+				continue
+			}
+
+			// Races against bots are not recorded
+			var opponent models.User
+			found := false
+			for opName := range stats {
+				if opName != username {
+					opponent, found = players[opName]
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+
+			result := models.Results{
+				UserID:     user.ID,
+				OpponentID: opponent.ID,
+				RoomID:     &room.ID,
+				PromptID:   room.PromptID,
+				Mode:       mode,
+				Duration:   room.Duration,
+				Won:        username == winnerUsername,
+				WPM:        s.WPM,
+				Accuracy:   s.Accuracy,
+				Error:      s.Error,
+
+				RawWPM:          s.RawWPM,
+				CorrectedErrors: s.CorrectedErrors,
+				Consistency:     s.Consistency,
+				Typed:           s.Typed,
+				Progress:        s.Progress,
+			}
+			if err := tx.Create(&result).Error; err != nil {
+				return err
+			}
+			if err := leaderboard.RecordResult(tx, result); err != nil {
+				return err
+			}
+			if err := prompts.RecordResult(tx, result); err != nil {
+				return err
+			}
+			set, err := records.RecordResult(tx, room, result)
+			if err != nil {
+				return err
+			}
+			if len(set) > 0 {
+				earned.PersonalBests[username] = set
+			}
+			if err := records.UpdateStreaks(tx, result); err != nil {
+				return err
+			}
+			unlocked, err := achievements.Evaluate(tx, result)
+			if err != nil {
+				return err
+			}
+			if len(unlocked) > 0 {
+				earned.Achievements[username] = unlocked
+			}
+			saved = append(saved, result)
+		}
+
+		if room.Ranked && len(saved) == 2 {
+			if err := rating.ApplyResults(tx, room.RoomCode, saved); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ranked against the last histogram, with the player's fresh average
+	now := time.Now()
+	for username, user := range players {
+		standing, err := percentile.Current.ForUser(config.DB, user.ID, now)
+		if err != nil {
+			log.Printf("Failed to rank %s: %v", username, err)
+			continue
+		}
+		if standing != nil {
+			earned.Percentiles[username] = standing
+		}
+	}
+	return earned, nil
+}
+
+// saveKeystrokes adds each player's keystrokes to their key stats
+func saveKeystrokes(tallies map[string]*keystats.Tally) {
+	now := time.Now()
+	for username, tally := range tallies {
+		if IsBotUsername(username) || tally.Empty() {
+			continue
+		}
+		var user models.User
+		if err := config.DB.Where("username = ?", username).First(&user).Error; err != nil {
+			continue
+		}
+		if err := keystats.Record(config.DB, user.ID, now, tally); err != nil {
+			log.Printf("Failed to save key stats for %s: %v", username, err)
+		}
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/Nitesh-04/realtime-racing/config"
+	"github.com/Nitesh-04/realtime-racing/keystats"
 	"github.com/Nitesh-04/realtime-racing/models"
+	"github.com/Nitesh-04/realtime-racing/scoring"
 	"github.com/gofiber/websocket/v2"
 )
 
@@ -17,6 +19,10 @@ type GameHub struct {
 	stats       map[string]map[string]PlayerStats
 	timers      map[string]*time.Timer
 	gameStates  map[string]GameState
+	tickets     map[string]*roomTickets
+	prompts     map[string]RacePrompt // room code -> prompt, for server-side scoring
+	keystrokes  map[string]map[string]*keystats.Tally // room code -> username -> keystrokes
+	trackers    map[string]map[string]*tracker        // room code -> username -> typed text history
 	mu          sync.RWMutex
 }
 
@@ -24,6 +30,7 @@ type Connection struct {
 	Conn     *websocket.Conn
 	RoomCode string
 	Username string
+	IsBot    bool       // Server-side bot, has no underlying socket
 	writeMu  sync.Mutex // Add write mutex for each connection
 }
 
@@ -32,10 +39,84 @@ type Message struct {
 	Payload interface{} `json:"payload"`
 }
 
+// PlayerStats is a player's live race state, see scoring.Result for how each
+// figure is defined. WPM is the net speed and Error the uncorrected errors
+
 type PlayerStats struct {
-	WPM      int     `json:"wpm"`
-	Accuracy float64 `json:"accuracy"`
-	Error   float64 `json:"errors"`
+	WPM             int     `json:"wpm"`
+	RawWPM          int     `json:"raw_wpm"`
+	Accuracy        float64 `json:"accuracy"`
+	Error           float64 `json:"errors"`
+	CorrectedErrors int     `json:"corrected_errors"`
+	Consistency     float64 `json:"consistency"`
+	Typed           int     `json:"typed_chars,omitempty"` // Characters typed so far
+	Progress        float64 `json:"progress,omitempty"`    // Percentage of the prompt typed
+}
+
+// RacePrompt is the text a room races on, sent to each player as they join
+type RacePrompt struct {
+	Text         string            `json:"text"`
+	Mode         models.PromptMode `json:"mode"`
+	CodeLanguage string            `json:"code_language,omitempty"`
+	Language     string            `json:"language"`
+	Duration     int               `json:"duration"` // Race length in seconds
+	Practice     bool              `json:"practice"`
+}
+
+// minPlayers is how many players have to be connected for the race to start
+func (p RacePrompt) minPlayers() int {
+	if p.Practice {
+		return 1
+	}
+	return 2
+}
+
+// hasEnoughPlayers reports whether the room can race, the caller must hold h.mu
+func (h *GameHub) hasEnoughPlayers(roomCode string) bool {
+	return len(h.connections[roomCode]) >= h.prompts[roomCode].minPlayers()
+}
+
+func racePromptFor(room models.Room) RacePrompt {
+	mode := room.Mode
+	if mode == "" {
+		mode = models.PromptModeText
+	}
+	duration := room.Duration
+	if duration <= 0 {
+		duration = models.DefaultRaceDuration
+	}
+	language := room.Language
+	if language == "" {
+		language = "en"
+	}
+	return RacePrompt{
+		Text:         room.Prompt,
+		Mode:         mode,
+		CodeLanguage: room.CodeLanguage,
+		Language:     language,
+		Duration:     duration,
+		Practice:     room.Practice,
+	}
+}
+
+// tracker follows one player's snapshots of this prompt using the mode's rules
+type tracker struct {
+	*scoring.Tracker
+	code bool
+}
+
+func (p RacePrompt) newTracker() *tracker {
+	if p.Mode == models.PromptModeCode {
+		return &tracker{Tracker: scoring.NewCodeTracker(p.Text), code: true}
+	}
+	return &tracker{Tracker: scoring.NewTracker(p.Text, scoring.RuleFor(p.Language))}
+}
+
+func (t *tracker) Update(typed string, elapsed time.Duration) scoring.Result {
+	if t.code {
+		typed = scoring.StripIndent(typed)
+	}
+	return t.Tracker.Update(typed, elapsed)
 }
 
 type GameState struct {
@@ -49,9 +130,16 @@ var Hub = &GameHub{
 	stats:       make(map[string]map[string]PlayerStats),
 	timers:      make(map[string]*time.Timer),
 	gameStates:  make(map[string]GameState),
+	tickets:     make(map[string]*roomTickets),
+	prompts:     make(map[string]RacePrompt),
+	keystrokes:  make(map[string]map[string]*keystats.Tally),
+	trackers:    make(map[string]map[string]*tracker),
 }
 
 func (c *Connection) SafeWriteMessage(messageType int, data []byte) error {
+	if c.IsBot {
+		return nil // Bots have no socket, they read state from the hub directly
+	}
 	c.writeMu.Lock()
 	defer c.writeMu.Unlock()
 	return c.Conn.WriteMessage(messageType, data)
@@ -62,6 +150,7 @@ func (h *GameHub) HandleConnection(c *websocket.Conn) {
 	
 	roomCode := c.Params("room_code")
 	username := c.Query("username")
+	ticket := c.Query("ticket")
 
 	log.Printf("Connection details - room_code: '%s', username: '%s'", roomCode, username)
 
@@ -72,6 +161,13 @@ func (h *GameHub) HandleConnection(c *websocket.Conn) {
 		return
 	}
 
+	if IsBotUsername(username) {
+		log.Printf("Rejected reserved bot username '%s'", username)
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseUnsupportedData, "Username is reserved for bots"))
+		c.Close()
+		return
+	}
+
 	// Verify room exists
 	var room models.Room
 	if err := config.DB.Where("room_code = ?", roomCode).First(&room).Error; err != nil {
@@ -83,6 +179,14 @@ func (h *GameHub) HandleConnection(c *websocket.Conn) {
 
 	log.Printf("Room found: %s (ID: %v)", room.RoomCode, room.ID)
 
+	// Matchmade rooms only accept the players that were handed a ticket
+	if !h.checkTicket(roomCode, username, ticket) {
+		log.Printf("Invalid join ticket for '%s' in room '%s'", username, roomCode)
+		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Invalid join ticket"))
+		c.Close()
+		return
+	}
+
 	// Check if username is already connected to this room
 	h.mu.Lock()
 	for i := range h.connections[roomCode] {
@@ -97,7 +201,17 @@ func (h *GameHub) HandleConnection(c *websocket.Conn) {
 	}
 	h.mu.Unlock()
 
+	prompt := racePromptFor(room)
+
+	h.mu.Lock()
+	h.prompts[room.RoomCode] = prompt
+	h.mu.Unlock()
+
 	conn := &Connection{Conn: c, RoomCode: room.RoomCode, Username: username}
+
+	if promptMessage, err := json.Marshal(Message{Type: "prompt", Payload: prompt}); err == nil {
+		conn.SafeWriteMessage(websocket.TextMessage, promptMessage)
+	}
 	
 	// Add connection and handle game state
 	h.addConnection(conn)
@@ -174,11 +288,22 @@ func BroadcastStatsUpdate(roomCode, username string, stats PlayerStats) {
 	})
 }
 
-func BroadcastGameOver(roomCode string, winner string, stats map[string]PlayerStats, reason string) {
+// BroadcastGameOver ends the race for everyone in the room, earned may be nil
+
+func BroadcastGameOver(roomCode string, winner string, stats map[string]PlayerStats, reason string, earned *RaceRecords) {
 	payload := map[string]interface{}{
 		"winner": winner,
 		"stats":  stats,
 	}
+	if earned != nil && len(earned.PersonalBests) > 0 {
+		payload["personal_bests"] = earned.PersonalBests
+	}
+	if earned != nil && len(earned.Achievements) > 0 {
+		payload["achievements"] = earned.Achievements
+	}
+	if earned != nil && len(earned.Percentiles) > 0 {
+		payload["percentiles"] = earned.Percentiles
+	}
 	if reason != "" {
 		payload["reason"] = reason
 	}
@@ -253,8 +378,8 @@ func (h *GameHub) addConnection(conn *Connection) {
 			conn.SafeWriteMessage(websocket.TextMessage, []byte(`{"type":"start","payload":null}`))
 		}()
 	case "waiting":
-		// Only start countdown if we have exactly 2 players and no timer exists
-		if playerCount == 2 && h.timers[conn.RoomCode] == nil {
+		// Only start countdown once the room is full and no timer exists
+		if playerCount == h.prompts[conn.RoomCode].minPlayers() && h.timers[conn.RoomCode] == nil {
 			log.Printf("Starting pre-game countdown for room %s", conn.RoomCode)
 			// Update game state
 			countdownEnd := time.Now().Add(5 * time.Second)
@@ -288,7 +413,7 @@ func (h *GameHub) removeConnection(conn *Connection) {
 
 	conns := h.connections[conn.RoomCode]
 	for i, c := range conns {
-		if c.Conn == conn.Conn {
+		if c == conn {
 			h.connections[conn.RoomCode] = append(conns[:i], conns[i+1:]...)
 			break
 		}
@@ -308,8 +433,8 @@ func (h *GameHub) removeConnection(conn *Connection) {
 	h.BroadcastToRoom(conn.RoomCode, "player_list", players)
 	h.mu.Lock()
 
-	// Stop timer and reset game state if less than 2 players remain
-	if len(h.connections[conn.RoomCode]) < 2 {
+	// Stop timer and reset game state if the room is no longer full
+	if !h.hasEnoughPlayers(conn.RoomCode) {
 		if t, ok := h.timers[conn.RoomCode]; ok {
 			t.Stop()
 			delete(h.timers, conn.RoomCode)
@@ -337,11 +462,12 @@ func (h *GameHub) startPreGame(roomCode string) {
 		for i := countdownDuration; i > 0; i-- {
 			// Check if room still exists and has enough players
 			h.mu.RLock()
-			connections, exists := h.connections[roomCode]
+			_, exists := h.connections[roomCode]
+			enough := h.hasEnoughPlayers(roomCode)
 			gameState := h.gameStates[roomCode]
 			h.mu.RUnlock()
 			
-			if !exists || len(connections) < 2 || gameState.Stage != "countdown" {
+			if !exists || !enough || gameState.Stage != "countdown" {
 				log.Printf("Room %s countdown cancelled - not enough players or state changed", roomCode)
 				return
 			}
@@ -380,22 +506,32 @@ func (h *GameHub) startRace(roomCode string) {
 		oldTimer.Stop()
 	}
 	
-	h.timers[roomCode] = time.AfterFunc(15*time.Second, func() {
+	// Keystrokes and snapshots from an aborted earlier race don't belong to
+	// this one
+	delete(h.keystrokes, roomCode)
+	delete(h.trackers, roomCode)
+
+	duration := h.prompts[roomCode].Duration
+	if duration <= 0 {
+		duration = models.DefaultRaceDuration
+	}
+
+	h.timers[roomCode] = time.AfterFunc(time.Duration(duration)*time.Second, func() {
 		h.mu.Lock()
-		defer h.mu.Unlock()
-		
 		// Update game state to finished
 		if gameState, exists := h.gameStates[roomCode]; exists {
 			gameState.Stage = "finished"
 			h.gameStates[roomCode] = gameState
 		}
+		h.mu.Unlock()
 		
+		// declareWinner takes the lock itself
 		log.Printf("Race finished for room %s, declaring winner", roomCode)
 		h.declareWinner(roomCode)
 	})
 	h.mu.Unlock()
 	
-	log.Printf("Race timer started for room %s (15 seconds)", roomCode)
+	log.Printf("Race timer started for room %s (%d seconds)", roomCode, duration)
 }
 
 func (h *GameHub) handleMessage(conn *Connection, rawMsg []byte) {
@@ -407,25 +543,107 @@ func (h *GameHub) handleMessage(conn *Connection, rawMsg []byte) {
 
 	switch msg.Type {
 	case "stats_update":
-		var stats PlayerStats
-		if err := mapToStruct(msg.Payload, &stats); err != nil {
+		var update struct {
+			PlayerStats
+			Typed *string `json:"typed"` // Text typed so far, the only thing read from players
+		}
+		if err := mapToStruct(msg.Payload, &update); err != nil {
 			log.Printf("Invalid stats payload: %v", err)
 			return
 		}
 
+		// Bots run on the server and report their own stats, players only
+		// send what they typed and the server scores it
+		stats := update.PlayerStats
+		if !conn.IsBot {
+			var ok bool
+			if stats, ok = h.scoreTyped(conn, update.Typed); !ok {
+				return
+			}
+		}
+
 		h.mu.Lock()
 		h.stats[conn.RoomCode][conn.Username] = stats
 		h.mu.Unlock()
 
 		BroadcastStatsUpdate(conn.RoomCode, conn.Username, stats)
+
+	case "keystrokes":
+		var batch struct {
+			Events []keystats.Event `json:"events"`
+		}
+		if err := mapToStruct(msg.Payload, &batch); err != nil {
+			log.Printf("Invalid keystrokes payload: %v", err)
+			return
+		}
+
+		h.mu.Lock()
+		if h.gameStates[conn.RoomCode].Stage == "racing" {
+			if h.keystrokes[conn.RoomCode] == nil {
+				h.keystrokes[conn.RoomCode] = make(map[string]*keystats.Tally)
+			}
+			tally, ok := h.keystrokes[conn.RoomCode][conn.Username]
+			if !ok {
+				tally = keystats.NewTally(h.prompts[conn.RoomCode].Text)
+				h.keystrokes[conn.RoomCode][conn.Username] = tally
+			}
+			tally.Add(batch.Events)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// scoreTyped scores a player's typed text, only during a race
+func (h *GameHub) scoreTyped(conn *Connection, typed *string) (PlayerStats, bool) {
+	h.mu.Lock()
+	prompt := h.prompts[conn.RoomCode]
+	gameState := h.gameStates[conn.RoomCode]
+
+	if typed == nil || prompt.Text == "" || gameState.Stage != "racing" {
+		h.mu.Unlock()
+		if typed == nil {
+			log.Printf("Dropping stats_update from '%s' without typed text", conn.Username)
+		}
+		return PlayerStats{}, false
 	}
+
+	if h.trackers[conn.RoomCode] == nil {
+		h.trackers[conn.RoomCode] = make(map[string]*tracker)
+	}
+	t := h.trackers[conn.RoomCode][conn.Username]
+	if t == nil {
+		t = prompt.newTracker()
+		h.trackers[conn.RoomCode][conn.Username] = t
+	}
+	h.mu.Unlock()
+
+	// Each player's messages are handled in order on their own socket
+	result := t.Update(*typed, time.Since(gameState.StartTime))
+	return PlayerStats{
+		WPM:             result.WPM,
+		RawWPM:          result.RawWPM,
+		Accuracy:        result.Accuracy,
+		Error:           float64(result.Errors),
+		CorrectedErrors: result.CorrectedErrors,
+		Consistency:     result.Consistency,
+		Typed:           result.Typed,
+		Progress:        result.Progress,
+	}, true
 }
 
 func (h *GameHub) declareWinner(roomCode string) {
+	// Snapshot the stats so the lock isn't held while talking to the DB
 	h.mu.Lock()
-	defer h.mu.Unlock()
+	stats := make(map[string]PlayerStats, len(h.stats[roomCode]))
+	for user, s := range h.stats[roomCode] {
+		stats[user] = s
+	}
+	tallies := h.keystrokes[roomCode]
+	delete(h.keystrokes, roomCode)
+	h.mu.Unlock()
+
+	saveKeystrokes(tallies)
 
-	stats := h.stats[roomCode]
 	var winnerUsername string
 	var bestStats PlayerStats
 
@@ -439,46 +657,21 @@ func (h *GameHub) declareWinner(roomCode string) {
 		}
 	}
 
+	var earned *RaceRecords
 	if winnerUsername != "" {
 		var room models.Room
 		if err := config.DB.Where("room_code = ?", roomCode).First(&room).Error; err == nil {
-			var winnerUser models.User
-			if err := config.DB.Where("username = ?", winnerUsername).First(&winnerUser).Error; err == nil {
-				// Update room winner
-				config.DB.Model(&models.Room{}).
-					Where("id = ?", room.ID).
-					Update("winner_id", winnerUser.ID)
-
-				// Insert results for each player
-				for username, s := range stats {
-					var user models.User
-					if err := config.DB.Where("username = ?", username).First(&user).Error; err == nil {
-						opponentName := ""
-						for opName := range stats {
-							if opName != username {
-								opponentName = opName
-								break
-							}
-						}
-						var opponent models.User
-						config.DB.Where("username = ?", opponentName).First(&opponent)
-
-						result := models.Results{
-							UserID:     user.ID,
-							OpponentID: opponent.ID,
-							Won:        (username == winnerUsername),
-							WPM:        s.WPM,
-							Accuracy:   s.Accuracy,
-							Error:      s.Error,
-						}
-						config.DB.Create(&result)
-					}
-				}
+			var err error
+			if earned, err = saveResults(room, stats, winnerUsername); err != nil {
+				log.Printf("Failed to save results for room %s: %v", roomCode, err)
 			}
 		}
 	}
 
-	BroadcastGameOver(roomCode, winnerUsername, stats, "winner_declared")
+	BroadcastGameOver(roomCode, winnerUsername, stats, "winner_declared", earned)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	// Clean up timers
 	if t, ok := h.timers[roomCode]; ok {
@@ -498,6 +691,10 @@ func (h *GameHub) declareWinner(roomCode string) {
 		delete(h.connections, roomCode)
 		delete(h.stats, roomCode)
 		delete(h.gameStates, roomCode)
+		delete(h.tickets, roomCode)
+		delete(h.prompts, roomCode)
+		delete(h.keystrokes, roomCode)
+		delete(h.trackers, roomCode)
 		if t, ok := h.timers[roomCode]; ok {
 			t.Stop()
 			delete(h.timers, roomCode)
@@ -529,6 +726,13 @@ func (h *GameHub) PeriodicCleanup() {
 		}
 	}
 	
+	// Matchmade rooms nobody ever connected to
+	for roomCode, t := range h.tickets {
+		if _, active := h.connections[roomCode]; !active && time.Since(t.issuedAt) > ticketTTL {
+			emptyRooms = append(emptyRooms, roomCode)
+		}
+	}
+
 	// Clean up empty rooms
 	for _, roomCode := range emptyRooms {
 		log.Printf("Periodic cleanup: removing empty room %s", roomCode)
@@ -538,6 +742,10 @@ func (h *GameHub) PeriodicCleanup() {
 		delete(h.connections, roomCode)
 		delete(h.stats, roomCode)
 		delete(h.gameStates, roomCode)
+		delete(h.tickets, roomCode)
+		delete(h.prompts, roomCode)
+		delete(h.keystrokes, roomCode)
+		delete(h.trackers, roomCode)
 		if timer, exists := h.timers[roomCode]; exists {
 			timer.Stop()
 			delete(h.timers, roomCode)
@@ -0,0 +1,120 @@
+package websockets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Nitesh-04/realtime-racing/keystats"
+)
+
+func newTestHub() *GameHub {
+	return &GameHub{
+		connections: make(map[string][]*Connection),
+		stats:       make(map[string]map[string]PlayerStats),
+		timers:      make(map[string]*time.Timer),
+		gameStates:  make(map[string]GameState),
+		tickets:     make(map[string]*roomTickets),
+		prompts:     make(map[string]RacePrompt),
+		keystrokes:  make(map[string]map[string]*keystats.Tally),
+		trackers:    make(map[string]map[string]*tracker),
+	}
+}
+
+func TestHasEnoughPlayers(t *testing.T) {
+	tests := []struct {
+		name     string
+		practice bool
+		players  int
+		want     bool
+	}{
+		{"empty race", false, 0, false},
+		{"race with one player", false, 1, false},
+		{"full race", false, 2, true},
+		{"empty practice", true, 0, false},
+		{"solo practice", true, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub()
+			h.prompts["ROOM"] = RacePrompt{Practice: tt.practice}
+			for i := 0; i < tt.players; i++ {
+				h.connections["ROOM"] = append(h.connections["ROOM"], &Connection{RoomCode: "ROOM", IsBot: true})
+			}
+			if got := h.hasEnoughPlayers("ROOM"); got != tt.want {
+				t.Errorf("hasEnoughPlayers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A practice room's single player must still get the race started
+func TestPracticeCountdownStartsRace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the full countdown")
+	}
+
+	h := newTestHub()
+	const room = "PRACTICE"
+	h.prompts[room] = RacePrompt{Practice: true, Duration: 600}
+	h.connections[room] = []*Connection{{RoomCode: room, Username: "solo", IsBot: true}}
+	h.gameStates[room] = GameState{Stage: "countdown", CountdownEnd: time.Now().Add(5 * time.Second)}
+
+	h.startPreGame(room)
+	t.Cleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if timer, ok := h.timers[room]; ok {
+			timer.Stop()
+		}
+	})
+
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.RLock()
+		stage := h.gameStates[room].Stage
+		h.mu.RUnlock()
+		if stage == "racing" {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("practice room never started racing")
+}
+
+func TestStatsUpdateIsScoredByServer(t *testing.T) {
+	const room = "SCORED"
+	tests := []struct {
+		name    string
+		stage   string
+		payload string
+		stored  bool
+	}{
+		{"self-reported stats are dropped", "racing", `{"wpm":300,"accuracy":100}`, false},
+		{"typed text is scored", "racing", `{"wpm":300,"accuracy":100,"typed":"the quick"}`, true},
+		{"updates before the race are dropped", "countdown", `{"typed":"the quick"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHub()
+			h.prompts[room] = RacePrompt{Text: "the quick brown fox", Language: "en"}
+			h.stats[room] = make(map[string]PlayerStats)
+			h.gameStates[room] = GameState{Stage: tt.stage, StartTime: time.Now().Add(-6 * time.Second)}
+			conn := &Connection{RoomCode: room, Username: "player"}
+
+			h.handleMessage(conn, []byte(`{"type":"stats_update","payload":`+tt.payload+`}`))
+
+			stats, ok := h.stats[room]["player"]
+			if ok != tt.stored {
+				t.Fatalf("stored = %v, want %v", ok, tt.stored)
+			}
+			if ok && stats.WPM >= 300 {
+				t.Errorf("client WPM leaked into stats: %+v", stats)
+			}
+			if ok && stats.Progress <= 0 {
+				t.Errorf("typed text wasn't scored: %+v", stats)
+			}
+		})
+	}
+}